@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/config"
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+	"github.com/bulbahal/GoBigTech/services/shared/tracing"
+)
+
+type Config struct {
+	GRPCAddr string `yaml:"grpc_addr" env:"INVENTORY_GRPC_ADDR" flag:"grpc-addr" default:"127.0.0.1:50051" usage:"gRPC listen address"`
+	Mongo    struct {
+		URI      string `yaml:"uri" env:"INVENTORY_MONGO_URI" flag:"mongo-uri" default:"mongodb://localhost:27017" secret:"true" validate:"required" usage:"MongoDB connection URI"`
+		Database string `yaml:"database" env:"INVENTORY_MONGO_DATABASE" flag:"mongo-database" default:"appdb" validate:"required" usage:"MongoDB database"`
+	} `yaml:"mongo"`
+	NATSURL         string         `yaml:"nats_url" env:"NATS_URL" flag:"nats-url" default:"nats://localhost:4222" secret:"true" validate:"required" usage:"NATS server URL"`
+	SweepInterval   time.Duration  `yaml:"sweep_interval" env:"INVENTORY_SWEEP_INTERVAL" flag:"sweep-interval" default:"30s" usage:"how often expired reservations are released"`
+	ReservationTTL  time.Duration  `yaml:"reservation_ttl" env:"INVENTORY_RESERVATION_TTL" flag:"reservation-ttl" default:"15m" usage:"how long uncommitted reservations hold stock"`
+	MetricsAddr     string         `yaml:"metrics_addr" env:"INVENTORY_METRICS_ADDR" flag:"metrics-addr" default:"127.0.0.1:9091" usage:"Prometheus metrics listen address"`
+	Log             logging.Config `yaml:"log"`
+	Tracing         tracing.Config `yaml:"tracing"`
+	HealthInterval  time.Duration  `yaml:"health_interval" env:"INVENTORY_HEALTH_INTERVAL" flag:"health-interval" default:"5s" usage:"how often readiness checks run"`
+	ShutdownTimeout time.Duration  `yaml:"shutdown_timeout" env:"INVENTORY_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" default:"10s" usage:"how long in-flight RPCs may finish on shutdown"`
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	errs = append(errs,
+		config.CheckAddr("grpc_addr", c.GRPCAddr),
+		config.CheckAddr("metrics_addr", c.MetricsAddr),
+		c.Log.Validate(),
+		c.Tracing.Validate(),
+	)
+	if c.SweepInterval <= 0 {
+		errs = append(errs, errors.New("sweep_interval must be positive"))
+	}
+	if c.ReservationTTL <= 0 {
+		errs = append(errs, errors.New("reservation_ttl must be positive"))
+	}
+	if c.HealthInterval <= 0 {
+		errs = append(errs, errors.New("health_interval must be positive"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown_timeout must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -2,15 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
+	"github.com/bulbahal/GoBigTech/services/inventory/internal/events"
 	"github.com/bulbahal/GoBigTech/services/inventory/internal/repository"
+	"github.com/bulbahal/GoBigTech/services/inventory/internal/sweeper"
 	inventorypb "github.com/bulbahal/GoBigTech/services/inventory/v1"
+	"github.com/bulbahal/GoBigTech/services/shared/config"
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus"
+	"github.com/bulbahal/GoBigTech/services/shared/grpcserver"
+	"github.com/bulbahal/GoBigTech/services/shared/health"
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+	"github.com/bulbahal/GoBigTech/services/shared/metrics"
+	"github.com/bulbahal/GoBigTech/services/shared/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// healthCheckTimeout bounds one run of the readiness checks.
+const healthCheckTimeout = 2 * time.Second
+
 type server struct {
 	inventorypb.UnimplementedInventoryServiceServer
 	repo *repository.MongoInventoryRepository
@@ -27,40 +49,204 @@ func (s *server) GetStock(ctx context.Context, req *inventorypb.GetStockRequest)
 	}, nil
 }
 func (s *server) ReserveStock(ctx context.Context, req *inventorypb.ReserveStockRequest) (*inventorypb.ReserveStockResponse, error) {
-	if req.GetQuantity() <= 0 {
-		return nil, status.Error(codes.InvalidArgument, "quantity must be greater than 0")
+	if req.GetOrderId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+
+	items := make([]repository.StockItem, 0, len(req.GetItems()))
+	for _, it := range req.GetItems() {
+		items = append(items, repository.StockItem{ProductID: it.GetProductId(), Qty: it.GetQuantity()})
+	}
+	if len(items) == 0 {
+		items = append(items, repository.StockItem{ProductID: req.GetProductId(), Qty: req.GetQuantity()})
+	}
+
+	for _, it := range items {
+		if it.ProductID == "" {
+			return nil, status.Error(codes.InvalidArgument, "product_id is required")
+		}
+		if it.Qty <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "quantity must be greater than 0")
+		}
 	}
 
-	err := s.repo.Reserve(ctx, req.GetProductId(), req.GetQuantity())
+	err := s.repo.Reserve(ctx, req.GetOrderId(), items)
 	if err != nil {
-		if err == repository.ErrNotEnoughStock {
+		if errors.Is(err, repository.ErrNotEnoughStock) {
+			reservationsRejected.WithLabelValues(rejectInsufficientStock).Inc()
 			return nil, status.Error(codes.FailedPrecondition, "not enough stock")
 		}
+		if errors.Is(err, repository.ErrReservationReleased) {
+			reservationsRejected.WithLabelValues(rejectReleased).Inc()
+			return nil, status.Error(codes.FailedPrecondition, "reservation already released")
+		}
+		if errors.Is(err, repository.ErrReservationPending) {
+			return nil, status.Error(codes.Unavailable, "reservation in progress, retry later")
+		}
 		return nil, status.Errorf(codes.Internal, "mongo reserve: %v", err)
 	}
 	return &inventorypb.ReserveStockResponse{Success: true}, nil
 }
 
+func (s *server) CommitStock(ctx context.Context, req *inventorypb.CommitStockRequest) (*inventorypb.CommitStockResponse, error) {
+	if req.GetOrderId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+
+	if err := s.repo.Commit(ctx, req.GetOrderId()); err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, status.Error(codes.NotFound, "reservation not found")
+		}
+		if errors.Is(err, repository.ErrReservationReleased) {
+			return nil, status.Error(codes.FailedPrecondition, "reservation already released")
+		}
+		if errors.Is(err, repository.ErrReservationPending) {
+			return nil, status.Error(codes.Unavailable, "reservation in progress, retry later")
+		}
+		return nil, status.Errorf(codes.Internal, "mongo commit: %v", err)
+	}
+	return &inventorypb.CommitStockResponse{Success: true}, nil
+}
+
+func (s *server) ReleaseStock(ctx context.Context, req *inventorypb.ReleaseStockRequest) (*inventorypb.ReleaseStockResponse, error) {
+	if req.GetOrderId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+
+	released, err := s.repo.Release(ctx, req.GetOrderId())
+	if err != nil {
+		if errors.Is(err, repository.ErrReservationNotFound) {
+			return nil, status.Error(codes.NotFound, "reservation not found")
+		}
+		return nil, status.Errorf(codes.Internal, "mongo release: %v", err)
+	}
+	return &inventorypb.ReleaseStockResponse{Success: true, Released: released}, nil
+}
+
+// main exits non-zero only after run has returned, so the deferred cleanup
+// still runs when the gRPC server fails.
 func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var cfg Config
+	config.MustLoad("inventory", &cfg)
+	logging.Setup("inventory", cfg.Log)
+
 	ctx := context.Background()
 
-	mongoClient, err := repository.ConnectMongo(ctx, "mongodb://localhost:27017")
+	shutdownTracing, err := tracing.Setup(ctx, "inventory", cfg.Tracing)
+	if err != nil {
+		logging.Fatal("tracing setup failed", "error", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("tracing shutdown failed", "error", err)
+		}
+	}()
+
+	poolMetrics := repository.NewPoolMetrics()
+	prometheus.MustRegister(poolMetrics)
+	mongoClient, err := repository.ConnectMongo(ctx, cfg.Mongo.URI, options.Client().
+		SetPoolMonitor(poolMetrics.Monitor()).
+		SetMonitor(otelmongo.NewMonitor()))
+	if err != nil {
+		logging.Fatal("mongo connect failed", "error", err)
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+		defer cancel()
+		if err := mongoClient.Disconnect(disconnectCtx); err != nil {
+			slog.Warn("mongo disconnect failed", "error", err)
+		}
+	}()
+
+	repo := repository.NewMongoInventoryRepository(mongoClient, cfg.Mongo.Database)
+	repo.SetReservationTTL(cfg.ReservationTTL)
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		logging.Fatal("mongo indexes failed", "error", err)
+	}
+	sweepCtx, stopSweeper := context.WithCancel(ctx)
+	sweeperDone := make(chan struct{})
+	go func() {
+		sweeper.New(repo, cfg.SweepInterval).Run(sweepCtx)
+		close(sweeperDone)
+	}()
+
+	bus, err := eventbus.ConnectNATS(cfg.NATSURL, "inventory", events.OrdersStream)
 	if err != nil {
-		log.Fatalf("mongo connect error: %v", err)
+		logging.Fatal("nats connect failed", "error", err)
+	}
+	defer func() {
+		if err := bus.Close(); err != nil {
+			slog.Warn("nats close failed", "error", err)
+		}
+	}()
+	if err := events.NewOrderEvents(repo).Subscribe(bus); err != nil {
+		logging.Fatal("nats subscribe failed", "error", err)
 	}
-	defer mongoClient.Disconnect(ctx)
 
-	repo := repository.NewMongoInventoryRepository(mongoClient, "appdb")
 	_ = repo.SetStock(ctx, "p1", 10)
 	_ = repo.SetStock(ctx, "p2", 10)
 
-	l, err := net.Listen("tcp", "127.0.0.1:50051")
+	l, err := net.Listen("tcp", cfg.GRPCAddr)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		logging.Fatal("listen failed", "addr", cfg.GRPCAddr, "error", err)
 	}
-	g := grpc.NewServer()
+	g := grpc.NewServer(
+		grpc.StatsHandler(tracing.ServerHandler()),
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()),
+	)
 	inventorypb.RegisterInventoryServiceServer(g, &server{repo: repo})
 
-	log.Println("inventory listening on 127.0.0.1:50051")
-	log.Fatal(g.Serve(l))
+	probe := health.NewProbe(healthCheckTimeout)
+	probe.Add("mongo", func(ctx context.Context) error {
+		return mongoClient.Ping(ctx, readpref.Primary())
+	})
+	healthServer := health.NewGRPCServer(probe, inventorypb.InventoryService_ServiceDesc.ServiceName)
+	healthServer.Register(g)
+	healthCtx, stopHealth := context.WithCancel(ctx)
+	defer stopHealth()
+	go healthServer.Watch(healthCtx, cfg.HealthInterval)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("inventory listening", "addr", cfg.GRPCAddr)
+		serveErr <- g.Serve(l)
+	}()
+
+	metricsSrv := metrics.NewServer(cfg.MetricsAddr)
+	go func() {
+		slog.Info("inventory metrics listening", "addr", cfg.MetricsAddr, "path", metrics.Path)
+		if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed", "error", err)
+		}
+	}()
+
+	sigCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	var failed error
+	select {
+	case <-sigCtx.Done():
+		slog.Info("inventory shutting down")
+	case failed = <-serveErr:
+		slog.Error("grpc server failed", "error", failed)
+	}
+
+	// Readiness goes down first, then RPCs and the sweeper stop; the deferred
+	// calls above drain the event subscription and disconnect Mongo.
+	healthServer.Shutdown()
+	if !grpcserver.GracefulStop(g, cfg.ShutdownTimeout) {
+		slog.Warn("in-flight RPCs cancelled", "after", cfg.ShutdownTimeout)
+	}
+	stopSweeper()
+	<-sweeperDone
+	_ = metricsSrv.Close()
+	slog.Info("inventory stopped")
+	return failed
 }
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	rejectInsufficientStock = "insufficient_stock"
+	rejectReleased          = "reservation_released"
+)
+
+var reservationsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "inventory_reservations_rejected_total",
+	Help: "Reservations refused, by reason.",
+}, []string{"reason"})
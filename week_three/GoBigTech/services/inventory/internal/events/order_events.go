@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bulbahal/GoBigTech/services/inventory/internal/repository"
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus"
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+)
+
+const (
+	OrderPaidSubject      = "orders.OrderPaid"
+	OrderCancelledSubject = "orders.OrderCancelled"
+	QueueGroup            = "inventory"
+)
+
+// OrdersStream is the stream the order service publishes its events to; the
+// declaration must match the one in the order service.
+var OrdersStream = eventbus.Stream{Name: "ORDERS", Subjects: []string{"orders.>"}}
+
+type Reservations interface {
+	Commit(ctx context.Context, orderID string) error
+	Release(ctx context.Context, orderID string) (int32, error)
+}
+
+type orderEvent struct {
+	OrderID string `json:"order_id"`
+}
+
+// OrderEvents reacts to events published by the order service.
+type OrderEvents struct {
+	repo Reservations
+}
+
+func NewOrderEvents(repo Reservations) *OrderEvents {
+	return &OrderEvents{repo: repo}
+}
+
+// Subscribe registers the handlers in the inventory queue group, so each
+// event is handled by a single inventory instance.
+func (e *OrderEvents) Subscribe(sub eventbus.Subscriber) error {
+	if _, err := sub.Subscribe(OrderPaidSubject, QueueGroup, e.HandleOrderPaid); err != nil {
+		return err
+	}
+	_, err := sub.Subscribe(OrderCancelledSubject, QueueGroup, e.HandleOrderCancelled)
+	return err
+}
+
+// HandleOrderPaid commits the reservation of a paid order. The order service
+// commits it right after saving the order; the event, stored together with
+// the order, finishes the commit when the order service stopped before.
+// Commit is idempotent, and a reservation already released belongs to an
+// order that was cancelled since, so both outcomes are final.
+func (e *OrderEvents) HandleOrderPaid(ctx context.Context, msg eventbus.Message) error {
+	ev, err := decodeOrderEvent(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx = logging.WithOrderID(ctx, ev.OrderID)
+	err = e.repo.Commit(ctx, ev.OrderID)
+	if errors.Is(err, repository.ErrReservationNotFound) || errors.Is(err, repository.ErrReservationReleased) {
+		slog.WarnContext(ctx, "order paid: reservation not committed", "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("commit order %s: %w", ev.OrderID, err)
+	}
+	return nil
+}
+
+// HandleOrderCancelled returns the stock reserved for a cancelled order.
+// The order service usually releases it synchronously already; Release is
+// idempotent, so the event only matters when that call was lost.
+func (e *OrderEvents) HandleOrderCancelled(ctx context.Context, msg eventbus.Message) error {
+	ev, err := decodeOrderEvent(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx = logging.WithOrderID(ctx, ev.OrderID)
+	released, err := e.repo.Release(ctx, ev.OrderID)
+	if errors.Is(err, repository.ErrReservationNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("release order %s: %w", ev.OrderID, err)
+	}
+	if released > 0 {
+		slog.InfoContext(ctx, "order cancelled: reservation released", "units", released)
+	}
+	return nil
+}
+
+func decodeOrderEvent(msg eventbus.Message) (orderEvent, error) {
+	var ev orderEvent
+	if err := json.Unmarshal(msg.Data, &ev); err != nil {
+		return orderEvent{}, fmt.Errorf("decode %s: %w", msg.Subject, err)
+	}
+	if ev.OrderID == "" {
+		return orderEvent{}, fmt.Errorf("%s without order_id", msg.Subject)
+	}
+	return ev, nil
+}
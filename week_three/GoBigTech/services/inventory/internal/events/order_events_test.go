@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bulbahal/GoBigTech/services/inventory/internal/repository"
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus"
+)
+
+type mockReservations struct {
+	err       error
+	committed []string
+	released  []string
+}
+
+func (m *mockReservations) Commit(ctx context.Context, orderID string) error {
+	m.committed = append(m.committed, orderID)
+	return m.err
+}
+
+func (m *mockReservations) Release(ctx context.Context, orderID string) (int32, error) {
+	m.released = append(m.released, orderID)
+	return 3, m.err
+}
+
+func TestOrderEvents_CommitsOnPaidAndReleasesOnCancel(t *testing.T) {
+	bus := eventbus.NewMemory()
+	repo := &mockReservations{}
+	if err := NewOrderEvents(repo).Subscribe(bus); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	_ = bus.Publish(context.Background(), "orders.OrderCreated", []byte(`{"order_id":"o0"}`))
+	_ = bus.Publish(context.Background(), OrderPaidSubject, []byte(`{"order_id":"o1","status":"paid"}`))
+	_ = bus.Publish(context.Background(), OrderCancelledSubject, []byte(`{"order_id":"o2","status":"cancelled"}`))
+
+	if len(repo.committed) != 1 || repo.committed[0] != "o1" {
+		t.Errorf("expected commit of o1 only, got %v", repo.committed)
+	}
+	if len(repo.released) != 1 || repo.released[0] != "o2" {
+		t.Errorf("expected release of o2 only, got %v", repo.released)
+	}
+}
+
+func TestHandleOrderPaid(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		repoErr error
+		wantErr bool
+	}{
+		{name: "ok", data: `{"order_id":"o1"}`},
+		{name: "unknown reservation", data: `{"order_id":"o1"}`, repoErr: repository.ErrReservationNotFound},
+		{name: "released reservation", data: `{"order_id":"o1"}`, repoErr: repository.ErrReservationReleased},
+		{name: "mongo down", data: `{"order_id":"o1"}`, repoErr: errors.New("mongo down"), wantErr: true},
+		{name: "missing order id", data: `{}`, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := NewOrderEvents(&mockReservations{err: c.repoErr})
+			err := e.HandleOrderPaid(context.Background(), eventbus.Message{Subject: OrderPaidSubject, Data: []byte(c.data)})
+			if (err != nil) != c.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHandleOrderCancelled(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		repoErr error
+		wantErr bool
+	}{
+		{name: "ok", data: `{"order_id":"o1"}`},
+		{name: "unknown reservation", data: `{"order_id":"o1"}`, repoErr: repository.ErrReservationNotFound},
+		{name: "bad json", data: `{`, wantErr: true},
+		{name: "missing order id", data: `{}`, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := NewOrderEvents(&mockReservations{err: c.repoErr})
+			err := e.HandleOrderCancelled(context.Background(), eventbus.Message{Subject: OrderCancelledSubject, Data: []byte(c.data)})
+			if (err != nil) != c.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
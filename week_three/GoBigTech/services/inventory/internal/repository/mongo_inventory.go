@@ -14,7 +14,14 @@ import (
 var ErrNotEnoughStock = errors.New("No enough stock")
 
 type MongoInventoryRepository struct {
-	col *mongo.Collection
+	col            *mongo.Collection
+	reservations   *mongo.Collection
+	reservationTTL time.Duration
+}
+
+type StockItem struct {
+	ProductID string
+	Qty       int32
 }
 
 type inventoryDoc struct {
@@ -23,8 +30,12 @@ type inventoryDoc struct {
 }
 
 func NewMongoInventoryRepository(client *mongo.Client, dbName string) *MongoInventoryRepository {
-	col := client.Database(dbName).Collection("inventory")
-	return &MongoInventoryRepository{col: col}
+	db := client.Database(dbName)
+	return &MongoInventoryRepository{
+		col:            db.Collection("inventory"),
+		reservations:   db.Collection("reservations"),
+		reservationTTL: DefaultReservationTTL,
+	}
 }
 
 func (r *MongoInventoryRepository) Get(ctx context.Context, productID string) (int32, error) {
@@ -39,7 +50,7 @@ func (r *MongoInventoryRepository) Get(ctx context.Context, productID string) (i
 	return doc.Qty, nil
 }
 
-func (r *MongoInventoryRepository) Reserve(ctx context.Context, productID string, qty int32) error {
+func (r *MongoInventoryRepository) take(ctx context.Context, productID string, qty int32) error {
 	filter := bson.M{
 		"product_id": productID,
 		"qty":        bson.M{"$gte": qty},
@@ -57,6 +68,17 @@ func (r *MongoInventoryRepository) Reserve(ctx context.Context, productID string
 	return nil
 }
 
+func (r *MongoInventoryRepository) restock(ctx context.Context, items []StockItem) error {
+	for _, item := range items {
+		filter := bson.M{"product_id": item.ProductID}
+		update := bson.M{"$inc": bson.M{"qty": item.Qty}}
+		if _, err := r.col.UpdateOne(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *MongoInventoryRepository) SetStock(ctx context.Context, productID string, qty int32) error {
 	filter := bson.M{"product_id": productID}
 	update := bson.M{"$set": bson.M{"qty": qty}}
@@ -67,10 +89,12 @@ func (r *MongoInventoryRepository) SetStock(ctx context.Context, productID strin
 	return nil
 }
 
-func ConnectMongo(ctx context.Context, uri string) (*mongo.Client, error) {
+// ConnectMongo connects to uri and pings the primary. opts are applied on
+// top of the URI, e.g. a pool monitor.
+func ConnectMongo(ctx context.Context, uri string, opts ...*options.ClientOptions) (*mongo.Client, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, append([]*options.ClientOptions{options.Client().ApplyURI(uri)}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
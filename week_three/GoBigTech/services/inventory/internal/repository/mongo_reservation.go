@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationReleased = errors.New("reservation already released")
+	ErrReservationPending  = errors.New("reservation in progress")
+)
+
+const DefaultReservationTTL = 15 * time.Minute
+
+const (
+	// ReservationPending marks a reservation whose items are being taken
+	// from stock.
+	ReservationPending   = "pending"
+	ReservationReserved  = "reserved"
+	ReservationCommitted = "committed"
+	// ReservationReleasing marks a reservation whose items are being
+	// returned to stock.
+	ReservationReleasing = "releasing"
+	ReservationReleased  = "released"
+)
+
+// releaseLease is how long a pending reservation or a release may go without
+// progress before Release or ReleaseExpired takes it over.
+const releaseLease = time.Minute
+
+type reservationItem struct {
+	ProductID string `bson:"product_id"`
+	Qty       int32  `bson:"qty"`
+}
+
+type reservationDoc struct {
+	OrderID   string            `bson:"order_id"`
+	Items     []reservationItem `bson:"items"`
+	Status    string            `bson:"status"`
+	CreatedAt time.Time         `bson:"created_at"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+	ExpiresAt time.Time         `bson:"expires_at"`
+	// Free holds the indexes of the items whose stock the reservation does
+	// not hold: those not taken yet while it is pending, and those already
+	// returned while it is being released.
+	Free []int `bson:"free,omitempty"`
+}
+
+// ExpiredStats describes what a ReleaseExpired call gave back to stock.
+type ExpiredStats struct {
+	Reservations int
+	Units        int32
+}
+
+// released reports whether the reservation is released or being released.
+func (d reservationDoc) released() bool {
+	return d.Status == ReservationReleasing || d.Status == ReservationReleased
+}
+
+// held returns nil when the reservation holds its stock, and otherwise why
+// it does not.
+func (d reservationDoc) held() error {
+	switch {
+	case d.Status == ReservationPending:
+		return ErrReservationPending
+	case d.released():
+		return ErrReservationReleased
+	}
+	return nil
+}
+
+func (d reservationDoc) stockItems() []StockItem {
+	items := make([]StockItem, len(d.Items))
+	for i, it := range d.Items {
+		items[i] = StockItem{ProductID: it.ProductID, Qty: it.Qty}
+	}
+	return items
+}
+
+// SetReservationTTL sets how long a reservation may stay uncommitted before
+// ReleaseExpired returns its stock.
+func (r *MongoInventoryRepository) SetReservationTTL(ttl time.Duration) {
+	r.reservationTTL = ttl
+}
+
+// EnsureIndexes creates the indexes the reservation model relies on.
+func (r *MongoInventoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.reservations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+	})
+	return err
+}
+
+// Reserve records a pending reservation for the order, takes its items from
+// stock one by one and then marks it reserved. Every item taken is recorded
+// on the reservation before the next one, so stock taken before a crash is
+// returned by ReleaseExpired once the pending reservation stops making
+// progress. When an item cannot be taken, the items taken before it go back
+// to stock and the reservation is removed, so the order may be reserved
+// later. Reserving again for an order that already has a reservation is a
+// no-op, so the call is safe to retry.
+func (r *MongoInventoryRepository) Reserve(ctx context.Context, orderID string, items []StockItem) error {
+	existing, err := r.findReservation(ctx, orderID)
+	if err == nil {
+		return existing.held()
+	}
+	if !errors.Is(err, ErrReservationNotFound) {
+		return err
+	}
+
+	now := time.Now().UTC()
+	doc := reservationDoc{
+		OrderID:   orderID,
+		Status:    ReservationPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(r.reservationTTL),
+	}
+	for i, it := range items {
+		doc.Items = append(doc.Items, reservationItem{ProductID: it.ProductID, Qty: it.Qty})
+		doc.Free = append(doc.Free, i)
+	}
+
+	if _, err := r.reservations.InsertOne(ctx, doc); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		// A concurrent call for the same order won the race.
+		existing, err := r.findReservation(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		return existing.held()
+	}
+
+	pending := bson.M{"order_id": orderID, "status": ReservationPending}
+	for i, it := range doc.stockItems() {
+		if err := r.take(ctx, it.ProductID, it.Qty); err != nil {
+			return errors.Join(err, r.abortReserve(context.WithoutCancel(ctx), orderID))
+		}
+		update := bson.M{
+			"$pull": bson.M{"free": i},
+			"$set":  bson.M{"updated_at": time.Now().UTC()},
+		}
+		res, err := r.reservations.UpdateOne(ctx, pending, update)
+		if err != nil {
+			return errors.Join(err, r.abortReserve(context.WithoutCancel(ctx), orderID))
+		}
+		if res.MatchedCount == 0 {
+			// Released meanwhile without this item, which goes back here.
+			return errors.Join(ErrReservationReleased, r.restock(context.WithoutCancel(ctx), []StockItem{it}))
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"status": ReservationReserved, "updated_at": time.Now().UTC()}}
+	res, err := r.reservations.UpdateOne(ctx, pending, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrReservationReleased
+	}
+	return nil
+}
+
+// abortReserve returns what a pending reservation took from stock and
+// removes the reservation. One already claimed by a release is left to it.
+func (r *MongoInventoryRepository) abortReserve(ctx context.Context, orderID string) error {
+	filter := bson.M{"order_id": orderID, "status": ReservationPending}
+	update := bson.M{"$set": bson.M{"status": ReservationReleasing, "updated_at": time.Now().UTC()}}
+	var doc reservationDoc
+	err := r.reservations.FindOneAndUpdate(ctx, filter, update).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := r.finishRelease(ctx, doc); err != nil {
+		return err
+	}
+	_, err = r.reservations.DeleteOne(ctx, bson.M{"order_id": orderID, "status": ReservationReleased})
+	return err
+}
+
+// Commit finalizes a reservation after the order has been paid.
+func (r *MongoInventoryRepository) Commit(ctx context.Context, orderID string) error {
+	filter := bson.M{"order_id": orderID, "status": ReservationReserved}
+	update := bson.M{"$set": bson.M{"status": ReservationCommitted, "updated_at": time.Now().UTC()}}
+	res, err := r.reservations.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 1 {
+		return nil
+	}
+
+	existing, err := r.findReservation(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return existing.held()
+}
+
+// Release returns the reserved quantities of the order to stock and reports
+// how many units came back. Pending, reserved and committed reservations
+// can be released; releasing twice returns zero units. The reservation is marked
+// releasing while its items go back to stock, and only marked released
+// once they all have, so an interrupted release is finished by the next
+// Release of the order or by ReleaseExpired.
+func (r *MongoInventoryRepository) Release(ctx context.Context, orderID string) (int32, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"order_id": orderID,
+		"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{ReservationPending, ReservationReserved, ReservationCommitted}}},
+			bson.M{"status": ReservationReleasing, "updated_at": bson.M{"$lte": now.Add(-releaseLease)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"status": ReservationReleasing, "updated_at": now}}
+
+	var doc reservationDoc
+	err := r.reservations.FindOneAndUpdate(ctx, filter, update).Decode(&doc)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, err
+		}
+		if _, err := r.findReservation(ctx, orderID); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	return r.finishRelease(ctx, doc)
+}
+
+// ReleaseExpired releases every uncommitted reservation that expired before
+// now, and finishes reservations and releases that were interrupted. Each reservation is
+// claimed atomically, so a concurrent Commit or Release of the same order
+// never returns the stock twice.
+func (r *MongoInventoryRepository) ReleaseExpired(ctx context.Context, now time.Time) (ExpiredStats, error) {
+	var stats ExpiredStats
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": ReservationReserved, "expires_at": bson.M{"$lte": now}},
+		bson.M{"status": bson.M{"$in": bson.A{ReservationPending, ReservationReleasing}}, "updated_at": bson.M{"$lte": now.Add(-releaseLease)}},
+	}}
+	update := bson.M{"$set": bson.M{"status": ReservationReleasing, "updated_at": now}}
+
+	for {
+		var doc reservationDoc
+		err := r.reservations.FindOneAndUpdate(ctx, filter, update).Decode(&doc)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return stats, nil
+			}
+			return stats, err
+		}
+
+		units, err := r.finishRelease(ctx, doc)
+		if err != nil {
+			return stats, err
+		}
+		stats.Reservations++
+		stats.Units += units
+	}
+}
+
+// finishRelease returns the items the reservation holds to stock and marks
+// it released. Every restocked item is recorded as free, so a release
+// resumed after a failure skips the items that already went back; only an
+// item restocked right before a crash, and not yet recorded, is returned
+// again. It ignores the cancellation of ctx, as a claimed release is better
+// finished than left for its lease to expire.
+func (r *MongoInventoryRepository) finishRelease(ctx context.Context, doc reservationDoc) (int32, error) {
+	ctx = context.WithoutCancel(ctx)
+
+	free := make(map[int]bool, len(doc.Free))
+	for _, i := range doc.Free {
+		free[i] = true
+	}
+
+	var units int32
+	for i, it := range doc.stockItems() {
+		if free[i] {
+			continue
+		}
+		if err := r.restock(ctx, []StockItem{it}); err != nil {
+			return 0, err
+		}
+		units += it.Qty
+		update := bson.M{
+			"$addToSet": bson.M{"free": i},
+			"$set":      bson.M{"updated_at": time.Now().UTC()},
+		}
+		if _, err := r.reservations.UpdateOne(ctx, bson.M{"order_id": doc.OrderID}, update); err != nil {
+			return 0, err
+		}
+	}
+
+	filter := bson.M{"order_id": doc.OrderID, "status": ReservationReleasing}
+	update := bson.M{"$set": bson.M{"status": ReservationReleased, "updated_at": time.Now().UTC()}}
+	if _, err := r.reservations.UpdateOne(ctx, filter, update); err != nil {
+		return 0, err
+	}
+	return units, nil
+}
+
+func (r *MongoInventoryRepository) findReservation(ctx context.Context, orderID string) (reservationDoc, error) {
+	var doc reservationDoc
+	err := r.reservations.FindOne(ctx, bson.M{"order_id": orderID}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return reservationDoc{}, ErrReservationNotFound
+		}
+		return reservationDoc{}, err
+	}
+	return doc, nil
+}
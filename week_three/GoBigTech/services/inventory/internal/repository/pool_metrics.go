@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// PoolMetrics tracks the Mongo connection pool through driver pool events.
+// Pass Monitor to ConnectMongo and register PoolMetrics as a collector.
+type PoolMetrics struct {
+	open      *prometheus.GaugeVec
+	inUse     *prometheus.GaugeVec
+	checkouts *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	waits     *prometheus.HistogramVec
+	clears    *prometheus.CounterVec
+}
+
+func NewPoolMetrics() *PoolMetrics {
+	labels := []string{"address"}
+	return &PoolMetrics{
+		open: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mongo_pool_open_connections",
+			Help: "Connections currently open, idle or in use.",
+		}, labels),
+		inUse: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "mongo_pool_in_use_connections",
+			Help: "Connections currently checked out.",
+		}, labels),
+		checkouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongo_pool_checkouts_total",
+			Help: "Successful connection checkouts.",
+		}, labels),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongo_pool_checkout_failures_total",
+			Help: "Failed connection checkouts, by reason.",
+		}, []string{"address", "reason"}),
+		waits: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "mongo_pool_checkout_seconds",
+			Help:    "Time taken to check out a connection.",
+			Buckets: []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 5},
+		}, labels),
+		clears: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mongo_pool_cleared_total",
+			Help: "Times the pool was cleared after a server error.",
+		}, labels),
+	}
+}
+
+func (m *PoolMetrics) Monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: m.handle}
+}
+
+func (m *PoolMetrics) handle(e *event.PoolEvent) {
+	switch e.Type {
+	case event.ConnectionCreated:
+		m.open.WithLabelValues(e.Address).Inc()
+	case event.ConnectionClosed:
+		m.open.WithLabelValues(e.Address).Dec()
+	case event.GetSucceeded:
+		m.inUse.WithLabelValues(e.Address).Inc()
+		m.checkouts.WithLabelValues(e.Address).Inc()
+		m.waits.WithLabelValues(e.Address).Observe(e.Duration.Seconds())
+	case event.ConnectionReturned:
+		m.inUse.WithLabelValues(e.Address).Dec()
+	case event.GetFailed:
+		m.failures.WithLabelValues(e.Address, e.Reason).Inc()
+	case event.PoolCleared:
+		m.clears.WithLabelValues(e.Address).Inc()
+	}
+}
+
+func (m *PoolMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.open.Describe(ch)
+	m.inUse.Describe(ch)
+	m.checkouts.Describe(ch)
+	m.failures.Describe(ch)
+	m.waits.Describe(ch)
+	m.clears.Describe(ch)
+}
+
+func (m *PoolMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.open.Collect(ch)
+	m.inUse.Collect(ch)
+	m.checkouts.Collect(ch)
+	m.failures.Collect(ch)
+	m.waits.Collect(ch)
+	m.clears.Collect(ch)
+}
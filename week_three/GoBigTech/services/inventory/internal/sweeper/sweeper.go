@@ -0,0 +1,55 @@
+package sweeper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/inventory/internal/repository"
+)
+
+type ExpiredReleaser interface {
+	ReleaseExpired(ctx context.Context, now time.Time) (repository.ExpiredStats, error)
+}
+
+// Sweeper periodically returns the stock of expired reservations, so that
+// abandoned or crashed order flows do not hold it forever.
+type Sweeper struct {
+	repo     ExpiredReleaser
+	interval time.Duration
+	now      func() time.Time
+}
+
+func New(repo ExpiredReleaser, interval time.Duration) *Sweeper {
+	return &Sweeper{
+		repo:     repo,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.SweepOnce(ctx); err != nil {
+				slog.ErrorContext(ctx, "reservation sweeper failed", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Sweeper) SweepOnce(ctx context.Context) (repository.ExpiredStats, error) {
+	stats, err := s.repo.ReleaseExpired(ctx, s.now().UTC())
+	if stats.Reservations > 0 {
+		slog.InfoContext(ctx, "reservation sweeper: released expired reservations",
+			"reservations", stats.Reservations, "units", stats.Units)
+	}
+	return stats, err
+}
@@ -0,0 +1,73 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/inventory/internal/repository"
+)
+
+type mockReleaser struct {
+	stats repository.ExpiredStats
+	err   error
+
+	calledAt time.Time
+}
+
+func (m *mockReleaser) ReleaseExpired(ctx context.Context, now time.Time) (repository.ExpiredStats, error) {
+	m.calledAt = now
+	return m.stats, m.err
+}
+
+func TestSweepOnce_ReportsReclaimedUnits(t *testing.T) {
+	now := time.Date(2025, 12, 1, 10, 0, 0, 0, time.UTC)
+	repo := &mockReleaser{stats: repository.ExpiredStats{Reservations: 2, Units: 7}}
+
+	s := New(repo, time.Minute)
+	s.now = func() time.Time { return now }
+
+	stats, err := s.SweepOnce(context.Background())
+	if err != nil {
+		t.Fatalf("SweepOnce failed: %v", err)
+	}
+	if !repo.calledAt.Equal(now) {
+		t.Errorf("ReleaseExpired called with wrong time: %v", repo.calledAt)
+	}
+	if stats.Reservations != 2 || stats.Units != 7 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSweepOnce_ReturnsPartialStatsOnError(t *testing.T) {
+	repo := &mockReleaser{
+		stats: repository.ExpiredStats{Reservations: 1, Units: 3},
+		err:   errors.New("mongo down"),
+	}
+
+	stats, err := New(repo, time.Minute).SweepOnce(context.Background())
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if stats.Units != 3 {
+		t.Errorf("expected units reclaimed before the error to be reported, got %+v", stats)
+	}
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		New(&mockReleaser{}, time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Run did not return after cancel")
+	}
+}
@@ -4,62 +4,164 @@
 package orderapi
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/oapi-codegen/runtime"
+	strictnethttp "github.com/oapi-codegen/runtime/strictmiddleware/nethttp"
 )
 
 // Defines values for OrderStatus.
 const (
-	Paid     OrderStatus = "paid"
-	Pending  OrderStatus = "pending"
-	Rejected OrderStatus = "rejected"
+	Cancelled OrderStatus = "cancelled"
+	Delivered OrderStatus = "delivered"
+	Failed    OrderStatus = "failed"
+	Fulfilled OrderStatus = "fulfilled"
+	Paid      OrderStatus = "paid"
+	Pending   OrderStatus = "pending"
+	Refunded  OrderStatus = "refunded"
+	Reserved  OrderStatus = "reserved"
+	Shipped   OrderStatus = "shipped"
 )
 
+// CancelOrder defines model for CancelOrder.
+type CancelOrder struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
 // CreateOrder defines model for CreateOrder.
 type CreateOrder struct {
 	Items  []OrderItem `json:"items"`
 	UserId string      `json:"user_id"`
 }
 
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field Location of the invalid value, e.g. items.0.quantity or query.limit.
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Money Amount in minor units (e.g. kopecks) of an ISO 4217 currency.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
 // Order defines model for Order.
 type Order struct {
-	Id     string      `json:"id"`
-	Items  []OrderItem `json:"items"`
-	Status OrderStatus `json:"status"`
-	UserId string      `json:"user_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Id        string      `json:"id"`
+	Items     []OrderItem `json:"items"`
+	Status    OrderStatus `json:"status"`
+
+	// Total Amount in minor units (e.g. kopecks) of an ISO 4217 currency.
+	Total  Money  `json:"total"`
+	UserId string `json:"user_id"`
 }
 
-// OrderStatus defines model for Order.Status.
-type OrderStatus string
-
 // OrderItem defines model for OrderItem.
 type OrderItem struct {
 	ProductId string `json:"product_id"`
 	Quantity  int32  `json:"quantity"`
+
+	// UnitPrice Unit price at the moment the order was placed.
+	UnitPrice *Money `json:"unit_price,omitempty"`
+}
+
+// OrderList defines model for OrderList.
+type OrderList struct {
+	Items []Order `json:"items"`
+
+	// NextCursor Cursor of the next page; absent on the last page.
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// OrderStatus defines model for OrderStatus.
+type OrderStatus string
+
+// Problem RFC 7807 problem details, returned with every error response.
+type Problem struct {
+	// Code Machine-readable error code.
+	Code   string  `json:"code"`
+	Detail *string `json:"detail,omitempty"`
+
+	// Errors Field-level violations of a request that failed validation.
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Instance Path of the request that failed.
+	Instance *string `json:"instance,omitempty"`
+	Status   int32   `json:"status"`
+	Title    string  `json:"title"`
+
+	// TraceId Identifier to quote when reporting the problem; matches the request trace.
+	TraceId string `json:"trace_id"`
+
+	// Type Problem type, /problems/{code}.
+	Type string `json:"type"`
+}
+
+// GetOrdersParams defines parameters for GetOrders.
+type GetOrdersParams struct {
+	UserId       *string      `form:"user_id,omitempty" json:"user_id,omitempty"`
+	Status       *OrderStatus `form:"status,omitempty" json:"status,omitempty"`
+	CreatedAfter *time.Time   `form:"created_after,omitempty" json:"created_after,omitempty"`
+	Limit        *int32       `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor Opaque cursor taken from next_cursor of the previous page.
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// PostOrdersParams defines parameters for PostOrders.
+type PostOrdersParams struct {
+	// IdempotencyKey Client-generated key that makes retries safe. A retry with the same key and body replays the stored response; reusing the key with a different body is rejected with 409.
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
 }
 
 // PostOrdersJSONRequestBody defines body for PostOrders for application/json ContentType.
 type PostOrdersJSONRequestBody = CreateOrder
 
+// PostOrdersIdCancelJSONRequestBody defines body for PostOrdersIdCancel for application/json ContentType.
+type PostOrdersIdCancelJSONRequestBody = CancelOrder
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (GET /orders)
+	GetOrders(w http.ResponseWriter, r *http.Request, params GetOrdersParams)
+
 	// (POST /orders)
-	PostOrders(w http.ResponseWriter, r *http.Request)
+	PostOrders(w http.ResponseWriter, r *http.Request, params PostOrdersParams)
 
 	// (GET /orders/{id})
 	GetOrdersId(w http.ResponseWriter, r *http.Request, id string)
+
+	// (POST /orders/{id}/cancel)
+	PostOrdersIdCancel(w http.ResponseWriter, r *http.Request, id string)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
 
 type Unimplemented struct{}
 
+// (GET /orders)
+func (_ Unimplemented) GetOrders(w http.ResponseWriter, r *http.Request, params GetOrdersParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // (POST /orders)
-func (_ Unimplemented) PostOrders(w http.ResponseWriter, r *http.Request) {
+func (_ Unimplemented) PostOrders(w http.ResponseWriter, r *http.Request, params PostOrdersParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -68,6 +170,11 @@ func (_ Unimplemented) GetOrdersId(w http.ResponseWriter, r *http.Request, id st
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// (POST /orders/{id}/cancel)
+func (_ Unimplemented) PostOrdersIdCancel(w http.ResponseWriter, r *http.Request, id string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // ServerInterfaceWrapper converts contexts to parameters.
 type ServerInterfaceWrapper struct {
 	Handler            ServerInterface
@@ -77,11 +184,96 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// GetOrders operation middleware
+func (siw *ServerInterfaceWrapper) GetOrders(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOrdersParams
+
+	// ------------- Optional query parameter "user_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "created_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_after", r.URL.Query(), &params.CreatedAfter)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "created_after", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetOrders(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // PostOrders operation middleware
 func (siw *ServerInterfaceWrapper) PostOrders(w http.ResponseWriter, r *http.Request) {
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostOrdersParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostOrders(w, r)
+		siw.Handler.PostOrders(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -116,6 +308,31 @@ func (siw *ServerInterfaceWrapper) GetOrdersId(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
+// PostOrdersIdCancel operation middleware
+func (siw *ServerInterfaceWrapper) PostOrdersIdCancel(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostOrdersIdCancel(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -229,12 +446,493 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/orders", wrapper.GetOrders)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/orders", wrapper.PostOrders)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/orders/{id}", wrapper.GetOrdersId)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/orders/{id}/cancel", wrapper.PostOrdersIdCancel)
+	})
 
 	return r
 }
+
+type GetOrdersRequestObject struct {
+	Params GetOrdersParams
+}
+
+type GetOrdersResponseObject interface {
+	VisitGetOrdersResponse(w http.ResponseWriter) error
+}
+
+type GetOrders200JSONResponse OrderList
+
+func (response GetOrders200JSONResponse) VisitGetOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetOrders400ApplicationProblemPlusJSONResponse Problem
+
+func (response GetOrders400ApplicationProblemPlusJSONResponse) VisitGetOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetOrders500ApplicationProblemPlusJSONResponse Problem
+
+func (response GetOrders500ApplicationProblemPlusJSONResponse) VisitGetOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersRequestObject struct {
+	Params PostOrdersParams
+	Body   *PostOrdersJSONRequestBody
+}
+
+type PostOrdersResponseObject interface {
+	VisitPostOrdersResponse(w http.ResponseWriter) error
+}
+
+type PostOrders200JSONResponse Order
+
+func (response PostOrders200JSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrders400ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrders400ApplicationProblemPlusJSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrders409ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrders409ApplicationProblemPlusJSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrders422ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrders422ApplicationProblemPlusJSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(422)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrders500ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrders500ApplicationProblemPlusJSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrders502ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrders502ApplicationProblemPlusJSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrders503ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrders503ApplicationProblemPlusJSONResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetOrdersIdRequestObject struct {
+	Id string `json:"id"`
+}
+
+type GetOrdersIdResponseObject interface {
+	VisitGetOrdersIdResponse(w http.ResponseWriter) error
+}
+
+type GetOrdersId200JSONResponse Order
+
+func (response GetOrdersId200JSONResponse) VisitGetOrdersIdResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetOrdersId404ApplicationProblemPlusJSONResponse Problem
+
+func (response GetOrdersId404ApplicationProblemPlusJSONResponse) VisitGetOrdersIdResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetOrdersId500ApplicationProblemPlusJSONResponse Problem
+
+func (response GetOrdersId500ApplicationProblemPlusJSONResponse) VisitGetOrdersIdResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersIdCancelRequestObject struct {
+	Id   string `json:"id"`
+	Body *PostOrdersIdCancelJSONRequestBody
+}
+
+type PostOrdersIdCancelResponseObject interface {
+	VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error
+}
+
+type PostOrdersIdCancel200JSONResponse Order
+
+func (response PostOrdersIdCancel200JSONResponse) VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersIdCancel404ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrdersIdCancel404ApplicationProblemPlusJSONResponse) VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersIdCancel409ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrdersIdCancel409ApplicationProblemPlusJSONResponse) VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersIdCancel500ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrdersIdCancel500ApplicationProblemPlusJSONResponse) VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersIdCancel502ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrdersIdCancel502ApplicationProblemPlusJSONResponse) VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostOrdersIdCancel503ApplicationProblemPlusJSONResponse Problem
+
+func (response PostOrdersIdCancel503ApplicationProblemPlusJSONResponse) VisitPostOrdersIdCancelResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+
+	// (GET /orders)
+	GetOrders(ctx context.Context, request GetOrdersRequestObject) (GetOrdersResponseObject, error)
+
+	// (POST /orders)
+	PostOrders(ctx context.Context, request PostOrdersRequestObject) (PostOrdersResponseObject, error)
+
+	// (GET /orders/{id})
+	GetOrdersId(ctx context.Context, request GetOrdersIdRequestObject) (GetOrdersIdResponseObject, error)
+
+	// (POST /orders/{id}/cancel)
+	PostOrdersIdCancel(ctx context.Context, request PostOrdersIdCancelRequestObject) (PostOrdersIdCancelResponseObject, error)
+}
+
+type StrictHandlerFunc = strictnethttp.StrictHTTPHandlerFunc
+type StrictMiddlewareFunc = strictnethttp.StrictHTTPMiddlewareFunc
+
+type StrictHTTPServerOptions struct {
+	RequestErrorHandlerFunc  func(w http.ResponseWriter, r *http.Request, err error)
+	ResponseErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: StrictHTTPServerOptions{
+		RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		},
+		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		},
+	}}
+}
+
+func NewStrictHandlerWithOptions(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc, options StrictHTTPServerOptions) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: options}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+	options     StrictHTTPServerOptions
+}
+
+// GetOrders operation middleware
+func (sh *strictHandler) GetOrders(w http.ResponseWriter, r *http.Request, params GetOrdersParams) {
+	var request GetOrdersRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetOrders(ctx, request.(GetOrdersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetOrders")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetOrdersResponseObject); ok {
+		if err := validResponse.VisitGetOrdersResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PostOrders operation middleware
+func (sh *strictHandler) PostOrders(w http.ResponseWriter, r *http.Request, params PostOrdersParams) {
+	var request PostOrdersRequestObject
+
+	request.Params = params
+
+	var body PostOrdersJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.PostOrders(ctx, request.(PostOrdersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PostOrders")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(PostOrdersResponseObject); ok {
+		if err := validResponse.VisitPostOrdersResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetOrdersId operation middleware
+func (sh *strictHandler) GetOrdersId(w http.ResponseWriter, r *http.Request, id string) {
+	var request GetOrdersIdRequestObject
+
+	request.Id = id
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetOrdersId(ctx, request.(GetOrdersIdRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetOrdersId")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetOrdersIdResponseObject); ok {
+		if err := validResponse.VisitGetOrdersIdResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PostOrdersIdCancel operation middleware
+func (sh *strictHandler) PostOrdersIdCancel(w http.ResponseWriter, r *http.Request, id string) {
+	var request PostOrdersIdCancelRequestObject
+
+	request.Id = id
+
+	var body PostOrdersIdCancelJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.PostOrdersIdCancel(ctx, request.(PostOrdersIdCancelRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PostOrdersIdCancel")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(PostOrdersIdCancelResponseObject); ok {
+		if err := validResponse.VisitPostOrdersIdCancelResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/8xZX2/bOvL9KgP+fg+7WMV2/nS7cZ96g70L47abonf71BYBI45s3lCkQo6cGIG/+2JI",
+	"yZZlOU2xt0nfaEkczgzPnDOkH0TuyspZtBTE9EGEfIGljMMLaXM0l16h55+VdxV60hhfepTBWR7RqkIx",
+	"FYG8tnOxXmftE3f9B+Yk1pm48CgJD1jShOXu4P89FmIq/m+8dW3c+DWORmaEJdsttZ2lScebVaX3csUv",
+	"64D+SqthFz3e1tqjEtPPmw+zxoOvAxH8qtGof3rvBgIo+B0PFIbc64o0J0a8c7nkIbgCaIGg7VIarWAp",
+	"TY0Z4Gg+grjgaDK6raUlTStwHm5r9KuR0aWmkcgE3suyMuxN/2OR9QPLOtuynWfr8ho9lHUguEaQBAZl",
+	"IDjeN9DLTIpsY3UoMe+dxdV+8G9LV1sCbaHU1nmoraYAf4lB37gK85vwV06MtDD7/RLOTo5fQ157jzZf",
+	"cdS7GZbR2k5UZ+fnk0wUzpeSODeW/n62jUdbwjl6drC1ujNbfPz0i8hEKe/foZ3TQkxPI5o6vx7PTONR",
+	"x/xQcg4gPo/loK5kDGkTg5KER6RLHNrYQSBn/0vx9OslkKT6aSZ+T5+yEUfSfGtOwsj3lGSsxm1dNq61",
+	"4bbLZt1MHsx/jHdvDyrvVJ3T1YG8bmqsu0Ha0umJiEDRZV12WacDOIb6VeV1jhG6xlwWYvr5SRn6mvXK",
+	"6JPVBNEWly2zSOlKtGnoODq4kwEqI3NUo1Sp6tKalZiSr7Gf1k7QnRAPZu6dDvSn8PUQ3Cze01Ve+5Ao",
+	"dTfui/i8ZU7+FCo5xzcgrwOH72x8YWRIL0bfpLLD1N5FNHOE5Z39LCq0ig2xmYB+iZyzSsbUFbUptDHx",
+	"UVjoqoojhUYv0cdxHrUzfeGxqK2Kw0JqfvZ1oMA/eHdtsNzPxcdfL+D1PyavoUpfgEKS2oQMPFLtLSq4",
+	"07QAXKJfAXrvPHgMlbMB96k0dwr313gv84W2eMTwkdcGGzP8cU+DbKiLQucaLV0FcvnNEFklD3sq5AjQ",
+	"unq+gDgPCuehASR8EdXxFzFkKjoS9j2OgnxkcIkGltqZKLUhSgrwvmPgIpEEKeUQtTd+NBLZ06Db0fwB",
+	"/GobiDd537UPkhYtdAdc2U3oOFZxEB0tq70+8lgg68qgFmyZeiuHk/Nsn6j2yYk0GdyZKS6cLYzOaWgl",
+	"8jLHhiN3g5wptKQLjR7IwW3tCOFugRY8Vs6TtvMYf4PZN1BKyhcYdpPC1keD68YHe4ltCoDfZjBubIfx",
+	"A+N03cvr5u0gZJ+a7B6LxLdtFju6FKuqk659nllHyBQuqk3ahMQ88PbDTGRiiT6kKI9Hk9GEk+AqtLLS",
+	"YipOR5PRaaQfWsR9b1EzfRBzpIHeUwcKSSACFN6VYPEuptyBM4pHdeA9usFVwMih2m6qg/ki/pgpMRX/",
+	"QrpsMVpJL0ukuPTnB6F5rdivikxYWXJQHdWOdTQo9sNTN+ncznxyK3LI5qZDKAj9jumn9F2HrMbufMea",
+	"wkLWhsT0ZDJQiKW8bzqGyeTx/mGd9ffyspK3NUKSSiB5g7bd0o2CtnRTeVxqV4eNKA6mJE55dIO+ZqKV",
+	"kAiyk8kkiYclTI24rCqj0wFn/Edz5PiObYutRSyKXrC/MfLPHl2uqey/fd+yrb4OLDprTmaFNoSez2BN",
+	"itaZePW8rqRKg9zVRgFL5jWC0YFQpa8rl1qy3RL94MLBGu01VoZp8GiOluejYgJI4lTKGwzgkbzGAEEW",
+	"OIK38fcq9ReMryBLjFOkVXDt1Irp3shV4vVAzqPaNB9vwGNiGVqkWdGOBKWLSLiUTGhellmy7WTOJuej",
+	"L7ZF7wKlQr+F70xhWTniQ9fRb7jawXHnQHfy6tXOke44G0Z5lKJfnFr9aQDv3nasdxUkNuU/urZ+mrp6",
+	"Lw0zYYREUnznN9cgrj0YnE3On9Opf/f60IydYoD2cBUPVgxgVPu47cSTTmKa9dNsnusAgbQxfAFSeTf3",
+	"GMIXG6M9OXnOaD/ZG+vubNtoZ1Dqe1TtVQtXOnfhclWmqFIVvgDv/YcvyLYbkKik7d2dB2kdLZDvkfC+",
+	"SlSRjiguj7E0Pj9rbt+Ccnc2kEdZAh8Rdd66nLw5fXFvdIDayqXUhk91PG2dtc3j+EGrdaeDPND1zdSB",
+	"vo+b0S0rayX6RPeiDcZjJHj27IoepbxwtVUv1VP0WgonFaoBPIzTxQWv9q1WY6bSHwQ/DB4/QJk7/2is",
+	"G2l+dhCm3Wjvh96AptDciMRGiO/mUcUGa8vLzQXST4HeZ9brTb4a6G4yx9rKuUtaRhA2l9LPXF+ftpoU",
+	"Lwp9kqYXUKSPET1tx51A5XyDn/Zxi6qfXqnW6/8OAPFUiAadHAAA",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
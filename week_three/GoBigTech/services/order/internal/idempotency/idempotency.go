@@ -0,0 +1,44 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Record is what is stored for an Idempotency-Key. Completed is false while
+// the request that claimed the key is still being processed. OrderID is the
+// order the request creates, fixed when the key is first claimed.
+type Record struct {
+	Fingerprint string
+	OrderID     string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type Store interface {
+	// Claim reserves key for a request with the given fingerprint that
+	// creates order orderID. When the key is already taken it returns the
+	// stored record and claimed=false. A key whose request never completed
+	// may be claimed again; rec then holds the order ID of the first claim,
+	// so the retry repeats the same order rather than creating another.
+	Claim(ctx context.Context, key, fingerprint, orderID string) (rec Record, claimed bool, err error)
+	// Complete stores the response of the request that claimed key.
+	Complete(ctx context.Context, key string, rec Record) error
+	// Release forgets key so that the request can be retried, e.g. after
+	// a server-side failure.
+	Release(ctx context.Context, key string) error
+}
+
+// Fingerprint identifies a request by its method, path and body.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus"
+)
+
+// SubjectPrefix namespaces order events on the bus: an OrderCancelled event
+// is published to "orders.OrderCancelled".
+const SubjectPrefix = "orders."
+
+// Stream stores the order events until every consumer group has handled
+// them. Services consuming order events declare the same stream.
+var Stream = eventbus.Stream{Name: "ORDERS", Subjects: []string{SubjectPrefix + ">"}}
+
+// BusPublisher forwards outbox events to an event bus.
+type BusPublisher struct {
+	Bus eventbus.Publisher
+}
+
+func (p BusPublisher) Publish(ctx context.Context, event Event) error {
+	return p.Bus.Publish(ctx, SubjectPrefix+event.Type, event.Payload)
+}
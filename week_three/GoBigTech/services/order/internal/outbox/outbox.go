@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+const (
+	OrderCreated   = "OrderCreated"
+	OrderPaid      = "OrderPaid"
+	OrderCancelled = "OrderCancelled"
+)
+
+// Event is a domain event waiting in the outbox to be published.
+type Event struct {
+	ID          int64
+	AggregateID string
+	Type        string
+	Payload     []byte
+	CreatedAt   time.Time
+	Attempts    int
+}
+
+type OrderItemPayload struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type MoneyPayload struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type OrderPayload struct {
+	OrderID    string             `json:"order_id"`
+	UserID     string             `json:"user_id"`
+	Status     string             `json:"status"`
+	Total      MoneyPayload       `json:"total"`
+	Items      []OrderItemPayload `json:"items"`
+	Reason     string             `json:"reason,omitempty"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
+
+// EventTypeForStatus returns the event published when an order enters
+// status, if any.
+func EventTypeForStatus(status service.OrderStatus) (string, bool) {
+	switch status {
+	case service.StatusPaid:
+		return OrderPaid, true
+	case service.StatusCancelled:
+		return OrderCancelled, true
+	}
+	return "", false
+}
+
+func NewOrderEvent(eventType string, order service.Order, reason string, at time.Time) (Event, error) {
+	payload := OrderPayload{
+		OrderID:    order.ID,
+		UserID:     order.UserID,
+		Status:     string(order.Status),
+		Total:      MoneyPayload{Amount: order.Total.Amount, Currency: order.Total.Currency},
+		Items:      make([]OrderItemPayload, len(order.Items)),
+		Reason:     reason,
+		OccurredAt: at,
+	}
+	for i, it := range order.Items {
+		payload.Items[i] = OrderItemPayload{ProductID: it.ProductID, Quantity: it.Quantity}
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{AggregateID: order.ID, Type: eventType, Payload: b, CreatedAt: at}, nil
+}
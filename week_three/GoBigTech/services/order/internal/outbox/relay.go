@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type Store interface {
+	// Claim leases up to limit due events. A claimed event is not returned
+	// again until the lease expires, so a crashed relay only delays it.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, nextAttempt time.Time, cause error) error
+}
+
+const (
+	DefaultBatchSize  = 100
+	DefaultLease      = 30 * time.Second
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Relay moves events from the outbox to a Publisher. Delivery is
+// at-least-once: an event is marked published only after Publish succeeds,
+// and failed events are retried with exponential backoff.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewRelay(store Store, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Run relays events every interval until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RelayOnce(ctx); err != nil {
+				slog.ErrorContext(ctx, "outbox relay failed", "error", err)
+			}
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due events and reports how many were
+// published.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.store.Claim(ctx, DefaultBatchSize, DefaultLease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, e := range events {
+		if err := r.publisher.Publish(ctx, e); err != nil {
+			next := r.now().Add(Backoff(e.Attempts + 1))
+			slog.WarnContext(logging.WithOrderID(ctx, e.AggregateID), "outbox relay: publish failed",
+				"event_type", e.Type, "event_id", e.ID, "attempt", e.Attempts+1, "retry_at", next, "error", err)
+			if markErr := r.store.MarkFailed(ctx, e.ID, next, err); markErr != nil {
+				return published, markErr
+			}
+			continue
+		}
+		if err := r.store.MarkPublished(ctx, e.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// Backoff returns the delay before the given attempt: DefaultMinBackoff
+// doubled for every previous failure, capped at DefaultMaxBackoff.
+func Backoff(attempt int) time.Duration {
+	d := DefaultMinBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= DefaultMaxBackoff {
+			return DefaultMaxBackoff
+		}
+	}
+	return d
+}
+
+// LogPublisher writes events to the default logger.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, event Event) error {
+	slog.InfoContext(logging.WithOrderID(ctx, event.AggregateID), "event",
+		"event_type", event.Type, "event_id", event.ID, "payload", string(event.Payload))
+	return nil
+}
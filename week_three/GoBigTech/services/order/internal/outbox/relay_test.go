@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus"
+)
+
+type mockStore struct {
+	events []Event
+
+	published []int64
+	failed    map[int64]time.Time
+}
+
+func (m *mockStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error) {
+	return m.events, nil
+}
+
+func (m *mockStore) MarkPublished(ctx context.Context, id int64) error {
+	m.published = append(m.published, id)
+	return nil
+}
+
+func (m *mockStore) MarkFailed(ctx context.Context, id int64, nextAttempt time.Time, cause error) error {
+	if m.failed == nil {
+		m.failed = make(map[int64]time.Time)
+	}
+	m.failed[id] = nextAttempt
+	return nil
+}
+
+type mockPublisher struct {
+	failFor map[int64]bool
+	sent    []int64
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event Event) error {
+	if m.failFor[event.ID] {
+		return errors.New("broker down")
+	}
+	m.sent = append(m.sent, event.ID)
+	return nil
+}
+
+func TestRelayOnce_PublishesAndSchedulesRetries(t *testing.T) {
+	now := time.Date(2026, 1, 25, 10, 0, 0, 0, time.UTC)
+	store := &mockStore{events: []Event{
+		{ID: 1, Type: OrderCreated},
+		{ID: 2, Type: OrderPaid, Attempts: 2},
+		{ID: 3, Type: OrderCancelled},
+	}}
+	pub := &mockPublisher{failFor: map[int64]bool{2: true}}
+
+	relay := NewRelay(store, pub, time.Second)
+	relay.now = func() time.Time { return now }
+
+	n, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RelayOnce failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 events published, got %d", n)
+	}
+	if len(store.published) != 2 || store.published[0] != 1 || store.published[1] != 3 {
+		t.Errorf("wrong events marked published: %v", store.published)
+	}
+	next, ok := store.failed[2]
+	if !ok {
+		t.Fatalf("expected failed event to be rescheduled")
+	}
+	if want := now.Add(4 * time.Second); !next.Equal(want) {
+		t.Errorf("retry scheduled at %v, want %v", next, want)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		10: DefaultMaxBackoff,
+		50: DefaultMaxBackoff,
+	}
+	for attempt, want := range cases {
+		if got := Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestBusPublisher_UsesEventTypeSubject(t *testing.T) {
+	bus := eventbus.NewMemory()
+
+	var got eventbus.Message
+	_, _ = bus.Subscribe("orders.OrderCancelled", "", func(ctx context.Context, msg eventbus.Message) error {
+		got = msg
+		return nil
+	})
+
+	pub := BusPublisher{Bus: bus}
+	if err := pub.Publish(context.Background(), Event{Type: OrderCancelled, Payload: []byte(`{"order_id":"o1"}`)}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if got.Subject != "orders.OrderCancelled" || string(got.Data) != `{"order_id":"o1"}` {
+		t.Errorf("unexpected message %+v", got)
+	}
+}
+
+func TestRelayOnce_KeepsEventsWhileBrokerDown(t *testing.T) {
+	bus, err := eventbus.ConnectNATS("nats://127.0.0.1:1", "test", Stream)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer bus.Close()
+
+	store := &mockStore{events: []Event{{ID: 1, Type: OrderCancelled, Payload: []byte(`{"order_id":"o1"}`)}}}
+	relay := NewRelay(store, BusPublisher{Bus: bus}, time.Second)
+
+	n, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RelayOnce failed: %v", err)
+	}
+	if n != 0 || len(store.published) != 0 {
+		t.Errorf("expected nothing published, got %d marked: %v", n, store.published)
+	}
+	if _, ok := store.failed[1]; !ok {
+		t.Error("expected the event to be rescheduled")
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+type PostgresCatalog struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCatalog(pool *pgxpool.Pool) *PostgresCatalog {
+	return &PostgresCatalog{
+		pool: pool,
+	}
+}
+
+func (c *PostgresCatalog) GetProducts(ctx context.Context, ids []string) (map[string]service.Product, error) {
+	query, args, err := psql.Select("id", "name", "unit_price_amount", "currency").From("products").Where(sq.Eq{"id": ids}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[string]service.Product, len(ids))
+	for rows.Next() {
+		var p service.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.UnitPrice.Amount, &p.UnitPrice.Currency); err != nil {
+			return nil, err
+		}
+		products[p.ID] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/idempotency"
+)
+
+// DefaultIdempotencyLockTimeout is how long an unfinished request keeps its
+// key. After that a retry with the same fingerprint may claim it again, for
+// the same order ID, so a crashed request does not block the key forever.
+const DefaultIdempotencyLockTimeout = time.Minute
+
+type PostgresIdempotencyStore struct {
+	pool        *pgxpool.Pool
+	lockTimeout time.Duration
+}
+
+func NewPostgresIdempotencyStore(pool *pgxpool.Pool) *PostgresIdempotencyStore {
+	return &PostgresIdempotencyStore{
+		pool:        pool,
+		lockTimeout: DefaultIdempotencyLockTimeout,
+	}
+}
+
+func (s *PostgresIdempotencyStore) Claim(ctx context.Context, key, fingerprint, orderID string) (idempotency.Record, bool, error) {
+	var claimedOrderID string
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (key, fingerprint, order_id)
+		VALUES ($1, $2, $4)
+		ON CONFLICT (key) DO UPDATE SET created_at = now(),
+		    order_id = COALESCE(idempotency_keys.order_id, EXCLUDED.order_id)
+		WHERE idempotency_keys.completed_at IS NULL
+		  AND idempotency_keys.fingerprint = EXCLUDED.fingerprint
+		  AND idempotency_keys.created_at < now() - $3::interval
+		RETURNING order_id`,
+		key, fingerprint, s.lockTimeout, orderID,
+	).Scan(&claimedOrderID)
+	if err == nil {
+		return idempotency.Record{Fingerprint: fingerprint, OrderID: claimedOrderID}, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return idempotency.Record{}, false, err
+	}
+
+	var rec idempotency.Record
+	var statusCode *int
+	var contentType, storedOrderID *string
+	err = s.pool.QueryRow(ctx,
+		`SELECT fingerprint, order_id, completed_at IS NOT NULL, status_code, content_type, response_body FROM idempotency_keys WHERE key = $1`,
+		key,
+	).Scan(&rec.Fingerprint, &storedOrderID, &rec.Completed, &statusCode, &contentType, &rec.Body)
+	if err != nil {
+		return idempotency.Record{}, false, err
+	}
+	if statusCode != nil {
+		rec.StatusCode = *statusCode
+	}
+	if contentType != nil {
+		rec.ContentType = *contentType
+	}
+	if storedOrderID != nil {
+		rec.OrderID = *storedOrderID
+	}
+	return rec, false, nil
+}
+
+func (s *PostgresIdempotencyStore) Complete(ctx context.Context, key string, rec idempotency.Record) error {
+	completeSQL, completeArgs, err := psql.Update("idempotency_keys").
+		Set("status_code", rec.StatusCode).
+		Set("content_type", rec.ContentType).
+		Set("response_body", rec.Body).
+		Set("completed_at", time.Now().UTC()).
+		Where("key = ?", key).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, completeSQL, completeArgs...)
+	return err
+}
+
+func (s *PostgresIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND completed_at IS NULL`, key)
+	return err
+}
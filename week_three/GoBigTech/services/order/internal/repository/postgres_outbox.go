@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/outbox"
+)
+
+type PostgresOutbox struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresOutbox(pool *pgxpool.Pool) *PostgresOutbox {
+	return &PostgresOutbox{
+		pool: pool,
+	}
+}
+
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, event outbox.Event) error {
+	eventSQL, eventArgs, err := psql.Insert("outbox").Columns("aggregate_id", "event_type", "payload", "created_at").Values(event.AggregateID, event.Type, event.Payload, event.CreatedAt).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, eventSQL, eventArgs...)
+	return err
+}
+
+func (o *PostgresOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Event, error) {
+	rows, err := o.pool.Query(ctx, `
+		UPDATE outbox SET next_attempt_at = now() + $2::interval
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, aggregate_id, event_type, payload, created_at, attempts`,
+		limit, lease,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []outbox.Event
+	for rows.Next() {
+		var e outbox.Event
+		if err := rows.Scan(&e.ID, &e.AggregateID, &e.Type, &e.Payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (o *PostgresOutbox) MarkPublished(ctx context.Context, id int64) error {
+	_, err := o.pool.Exec(ctx, `UPDATE outbox SET published_at = now(), last_error = NULL WHERE id = $1`, id)
+	return err
+}
+
+func (o *PostgresOutbox) MarkFailed(ctx context.Context, id int64, nextAttempt time.Time, cause error) error {
+	_, err := o.pool.Exec(ctx,
+		`UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1`,
+		id, nextAttempt, cause.Error(),
+	)
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+type quoteItem struct {
+	ProductID       string `json:"product_id"`
+	Quantity        int    `json:"quantity"`
+	UnitPriceAmount int64  `json:"unit_price_amount"`
+	Currency        string `json:"currency"`
+}
+
+func (r *PostgresRepository) GetQuote(ctx context.Context, orderID string) (service.Quote, bool, error) {
+	var items []byte
+	var quote service.Quote
+	err := r.pool.QueryRow(ctx,
+		`SELECT items, total_amount, currency FROM order_quotes WHERE order_id = $1`,
+		orderID,
+	).Scan(&items, &quote.Total.Amount, &quote.Total.Currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return service.Quote{}, false, nil
+	}
+	if err != nil {
+		return service.Quote{}, false, err
+	}
+	if quote.Items, err = decodeQuoteItems(items); err != nil {
+		return service.Quote{}, false, fmt.Errorf("quote of order %s: %w", orderID, err)
+	}
+	return quote, true, nil
+}
+
+// SaveQuote keeps the first quote of an order: a concurrent or later call
+// gets that one back instead of storing its own.
+func (r *PostgresRepository) SaveQuote(ctx context.Context, orderID string, quote service.Quote) (service.Quote, error) {
+	stored := make([]quoteItem, len(quote.Items))
+	for i, it := range quote.Items {
+		stored[i] = quoteItem{
+			ProductID:       it.ProductID,
+			Quantity:        it.Quantity,
+			UnitPriceAmount: it.UnitPrice.Amount,
+			Currency:        it.UnitPrice.Currency,
+		}
+	}
+	items, err := json.Marshal(stored)
+	if err != nil {
+		return service.Quote{}, err
+	}
+
+	var saved service.Quote
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO order_quotes (order_id, items, total_amount, currency)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (order_id) DO UPDATE SET order_id = order_quotes.order_id
+		RETURNING items, total_amount, currency`,
+		orderID, items, quote.Total.Amount, quote.Total.Currency,
+	).Scan(&items, &saved.Total.Amount, &saved.Total.Currency)
+	if err != nil {
+		return service.Quote{}, err
+	}
+	if saved.Items, err = decodeQuoteItems(items); err != nil {
+		return service.Quote{}, fmt.Errorf("quote of order %s: %w", orderID, err)
+	}
+	return saved, nil
+}
+
+func decodeQuoteItems(data []byte) ([]service.OrderItem, error) {
+	var stored []quoteItem
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	items := make([]service.OrderItem, len(stored))
+	for i, it := range stored {
+		items[i] = service.OrderItem{
+			ProductID: it.ProductID,
+			Quantity:  it.Quantity,
+			UnitPrice: service.Money{Amount: it.UnitPriceAmount, Currency: it.Currency},
+		}
+	}
+	return items, nil
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/bulbahal/GoBigTech/services/order/internal/outbox"
 	"github.com/bulbahal/GoBigTech/services/order/internal/service"
 )
 
@@ -29,7 +31,7 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order service.Order)
 		return err
 	}
 
-	orderSQL, orderArgs, err := psql.Insert("orders").Columns("id", "user_id", "status").Values(order.ID, order.UserID, order.Status).ToSql()
+	orderSQL, orderArgs, err := psql.Insert("orders").Columns("id", "user_id", "status", "total_amount", "currency", "payment_transaction_id", "created_at").Values(order.ID, order.UserID, string(order.Status), order.Total.Amount, order.Total.Currency, order.PaymentID, order.CreatedAt).ToSql()
 	if err != nil {
 		_ = tx.Rollback(ctx)
 		return err
@@ -40,7 +42,7 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order service.Order)
 		return err
 	}
 	for _, item := range order.Items {
-		itemSQL, itemArgs, err := psql.Insert("order_items").Columns("order_id", "product_id", "quantity").Values(order.ID, item.ProductID, item.Quantity).ToSql()
+		itemSQL, itemArgs, err := psql.Insert("order_items").Columns("order_id", "product_id", "quantity", "unit_price_amount", "currency").Values(order.ID, item.ProductID, item.Quantity, item.UnitPrice.Amount, item.UnitPrice.Currency).ToSql()
 		if err != nil {
 			_ = tx.Rollback(ctx)
 			return err
@@ -50,29 +52,109 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order service.Order)
 			return err
 		}
 	}
+	for _, change := range order.History {
+		if err := insertStatusChange(ctx, tx, order.ID, change); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	events := []string{outbox.OrderCreated}
+	if eventType, ok := outbox.EventTypeForStatus(order.Status); ok {
+		events = append(events, eventType)
+	}
+	for _, eventType := range events {
+		event, err := outbox.NewOrderEvent(eventType, order, "", order.CreatedAt)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+		if err := insertOutboxEvent(ctx, tx, event); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (r *PostgresRepository) UpdateStatus(ctx context.Context, order service.Order, change service.StatusChange) error {
+	id := order.ID
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	updateSQL, updateArgs, err := psql.Update("orders").Set("status", string(change.To)).Where(sq.Eq{"id": id, "status": string(change.From)}).ToSql()
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	tag, err := tx.Exec(ctx, updateSQL, updateArgs...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return service.ErrStatusConflict
+	}
+
+	if err := insertStatusChange(ctx, tx, id, change); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	if eventType, ok := outbox.EventTypeForStatus(change.To); ok {
+		event, err := outbox.NewOrderEvent(eventType, order, change.Reason, change.At)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+		if err := insertOutboxEvent(ctx, tx, event); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertStatusChange(ctx context.Context, tx pgx.Tx, orderID string, change service.StatusChange) error {
+	var from *string
+	if change.From != "" {
+		s := string(change.From)
+		from = &s
+	}
+	historySQL, historyArgs, err := psql.Insert("order_status_history").Columns("order_id", "from_status", "to_status", "reason", "created_at").Values(orderID, from, string(change.To), change.Reason, change.At).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, historySQL, historyArgs...)
+	return err
+}
+
 func (r *PostgresRepository) GetOrderByID(ctx context.Context, id string) (service.Order, error) {
 	var order service.Order
 	order.ID = id
 
 	row := r.pool.QueryRow(ctx,
-		`SELECT user_id, status FROM orders WHERE id = $1`,
+		`SELECT user_id, status, total_amount, currency, payment_transaction_id, created_at FROM orders WHERE id = $1`,
 		id,
 	)
-	if err := row.Scan(&order.UserID, &order.Status); err != nil {
+	if err := row.Scan(&order.UserID, &order.Status, &order.Total.Amount, &order.Total.Currency, &order.PaymentID, &order.CreatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return service.Order{}, errors.New("order not found")
+			return service.Order{}, fmt.Errorf("%w: %s", service.ErrOrderNotFound, id)
 		}
-		return service.Order{}, err
+		return service.Order{}, fmt.Errorf("load order %s: %w", id, err)
 	}
 
 	rows, err := r.pool.Query(ctx,
-		`SELECT product_id, quantity FROM order_items WHERE order_id = $1`,
+		`SELECT product_id, quantity, unit_price_amount, currency FROM order_items WHERE order_id = $1 ORDER BY id`,
 		id,
 	)
 	if err != nil {
@@ -82,7 +164,7 @@ func (r *PostgresRepository) GetOrderByID(ctx context.Context, id string) (servi
 
 	for rows.Next() {
 		var item service.OrderItem
-		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.UnitPrice.Amount, &item.UnitPrice.Currency); err != nil {
 			return service.Order{}, err
 		}
 		order.Items = append(order.Items, item)
@@ -94,3 +176,84 @@ func (r *PostgresRepository) GetOrderByID(ctx context.Context, id string) (servi
 
 	return order, nil
 }
+
+func (r *PostgresRepository) ListOrders(ctx context.Context, filter service.ListOrdersFilter, after *service.OrderCursor, limit int) ([]service.Order, error) {
+	query := psql.Select("id", "user_id", "status", "total_amount", "currency", "payment_transaction_id", "created_at").
+		From("orders").
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit))
+	if filter.UserID != "" {
+		query = query.Where(sq.Eq{"user_id": filter.UserID})
+	}
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"status": string(filter.Status)})
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where(sq.Gt{"created_at": filter.CreatedAfter})
+	}
+	if after != nil {
+		query = query.Where(sq.Expr("(created_at, id) < (?, ?)", after.CreatedAt, after.ID))
+	}
+
+	listSQL, listArgs, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, listSQL, listArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []service.Order
+	index := make(map[string]int)
+	for rows.Next() {
+		var o service.Order
+		if err := rows.Scan(&o.ID, &o.UserID, &o.Status, &o.Total.Amount, &o.Total.Currency, &o.PaymentID, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		index[o.ID] = len(orders)
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	itemsSQL, itemsArgs, err := psql.Select("order_id", "product_id", "quantity", "unit_price_amount", "currency").
+		From("order_items").
+		Where(sq.Eq{"order_id": ids}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	itemRows, err := r.pool.Query(ctx, itemsSQL, itemsArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var orderID string
+		var item service.OrderItem
+		if err := itemRows.Scan(&orderID, &item.ProductID, &item.Quantity, &item.UnitPrice.Amount, &item.UnitPrice.Currency); err != nil {
+			return nil, err
+		}
+		i := index[orderID]
+		orders[i].Items = append(orders[i].Items, item)
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
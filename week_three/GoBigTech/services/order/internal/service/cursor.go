@@ -0,0 +1,38 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OrderCursor points at the last order of a page. Orders are listed by
+// created_at and id descending, so the next page starts strictly after it.
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+func (c OrderCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeOrderCursor(s string) (OrderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return OrderCursor{}, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return OrderCursor{}, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return OrderCursor{}, ErrInvalidCursor
+	}
+	return OrderCursor{CreatedAt: createdAt, ID: id}, nil
+}
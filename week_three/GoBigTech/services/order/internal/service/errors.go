@@ -0,0 +1,16 @@
+package service
+
+import "errors"
+
+// Errors returned by OrderService, possibly wrapped. Repositories report a
+// missing order as ErrOrderNotFound; client adapters translate failures of
+// the downstream services into the others, so callers never have to know
+// about the transport those services use.
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrInvalidOrder      = errors.New("invalid order")
+	ErrUnknownProduct    = errors.New("unknown product")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrPaymentRejected   = errors.New("payment rejected")
+	ErrUnavailable       = errors.New("dependency unavailable")
+)
@@ -0,0 +1,18 @@
+package service
+
+import "github.com/google/uuid"
+
+type IDGenerator interface {
+	NewID() (string, error)
+}
+
+// UUIDGenerator produces time-sortable UUIDv7 identifiers.
+type UUIDGenerator struct{}
+
+func (UUIDGenerator) NewID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
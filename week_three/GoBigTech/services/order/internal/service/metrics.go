@@ -0,0 +1,13 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ordersCreated counts orders that went through the creation saga. Orders
+// whose saga failed and was compensated are counted as failed.
+var ordersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "orders_created_total",
+	Help: "Orders that went through the creation saga, by resulting status.",
+}, []string{"status"})
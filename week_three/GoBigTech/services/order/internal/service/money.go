@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrAmountOverflow   = errors.New("amount out of range")
+)
+
+// Money is an amount in minor units (kopecks, cents) of an ISO 4217
+// currency. All supported currencies have two minor digits.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) (Money, error) {
+	m := Money{Amount: amount, Currency: currency}
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+func (m Money) Validate() error {
+	if len(m.Currency) != 3 {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, m.Currency)
+	}
+	for _, c := range m.Currency {
+		if c < 'A' || c > 'Z' {
+			return fmt.Errorf("%w: %q", ErrInvalidCurrency, m.Currency)
+		}
+	}
+	return nil
+}
+
+// Add returns m + o. Amounts in different currencies are never summed.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrAmountOverflow, m, o)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Mul returns m * n, failing when the product does not fit an int64.
+func (m Money) Mul(n int64) (Money, error) {
+	product := m.Amount * n
+	if n != 0 && (product/n != m.Amount || (n == -1 && m.Amount == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrAmountOverflow, m, n)
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+func (m Money) String() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, amount/100, amount%100, m.Currency)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMoney_Add(t *testing.T) {
+	a := Money{Amount: 1999, Currency: "RUB"}
+	b := Money{Amount: 1, Currency: "RUB"}
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if sum != (Money{Amount: 2000, Currency: "RUB"}) {
+		t.Errorf("wrong sum: %v", sum)
+	}
+}
+
+func TestMoney_AddCurrencyMismatch(t *testing.T) {
+	_, err := Money{Amount: 100, Currency: "RUB"}.Add(Money{Amount: 100, Currency: "USD"})
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestMoney_AddOverflow(t *testing.T) {
+	cases := []struct{ a, b int64 }{
+		{math.MaxInt64, 1},
+		{math.MinInt64, -1},
+	}
+	for _, c := range cases {
+		_, err := Money{Amount: c.a, Currency: "RUB"}.Add(Money{Amount: c.b, Currency: "RUB"})
+		if !errors.Is(err, ErrAmountOverflow) {
+			t.Errorf("%d + %d: expected ErrAmountOverflow, got %v", c.a, c.b, err)
+		}
+	}
+}
+
+func TestMoney_Mul(t *testing.T) {
+	got, err := Money{Amount: 333, Currency: "EUR"}.Mul(3)
+	if err != nil {
+		t.Fatalf("Mul failed: %v", err)
+	}
+	if got != (Money{Amount: 999, Currency: "EUR"}) {
+		t.Errorf("wrong product: %v", got)
+	}
+}
+
+func TestMoney_MulOverflow(t *testing.T) {
+	cases := []struct{ amount, n int64 }{
+		{math.MaxInt64/2 + 1, 2},
+		{math.MinInt64, -1},
+		{-math.MaxInt64, 3},
+	}
+	for _, c := range cases {
+		if _, err := (Money{Amount: c.amount, Currency: "EUR"}).Mul(c.n); !errors.Is(err, ErrAmountOverflow) {
+			t.Errorf("%d * %d: expected ErrAmountOverflow, got %v", c.amount, c.n, err)
+		}
+	}
+	if got, err := (Money{Amount: math.MaxInt64, Currency: "EUR"}).Mul(1); err != nil || got.Amount != math.MaxInt64 {
+		t.Errorf("MaxInt64 * 1 = %v, %v", got, err)
+	}
+}
+
+func TestNewMoney_Validation(t *testing.T) {
+	for _, currency := range []string{"", "RU", "rub", "RUBL", "R1B"} {
+		if _, err := NewMoney(100, currency); !errors.Is(err, ErrInvalidCurrency) {
+			t.Errorf("currency %q: expected ErrInvalidCurrency, got %v", currency, err)
+		}
+	}
+	if _, err := NewMoney(100, "RUB"); err != nil {
+		t.Errorf("RUB rejected: %v", err)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	cases := map[Money]string{
+		{Amount: 4990, Currency: "RUB"}: "49.90 RUB",
+		{Amount: 5, Currency: "USD"}:    "0.05 USD",
+		{Amount: -150, Currency: "EUR"}: "-1.50 EUR",
+	}
+	for m, want := range cases {
+		if got := m.String(); got != want {
+			t.Errorf("%#v.String() = %q, want %q", m, got, want)
+		}
+	}
+}
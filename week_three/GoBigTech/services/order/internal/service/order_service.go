@@ -3,82 +3,450 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Order struct {
-	ID     string
-	UserID string
-	Status string
-	Items  []OrderItem
+	ID      string
+	UserID  string
+	Status  OrderStatus
+	Items   []OrderItem
+	Total   Money
+	History []StatusChange
+	// PaymentID is the payment transaction that paid for the order.
+	PaymentID string
+	CreatedAt time.Time
 }
 type OrderItem struct {
 	ProductID string
 	Quantity  int
+	// UnitPrice is a snapshot of the catalog price at the moment the order
+	// was placed.
+	UnitPrice Money
+}
+
+func (i OrderItem) LineTotal() (Money, error) {
+	return i.UnitPrice.Mul(int64(i.Quantity))
+}
+
+// Quote is the priced snapshot of an order: its items with their unit
+// prices and the total charged.
+type Quote struct {
+	Items []OrderItem
+	Total Money
+}
+
+type Product struct {
+	ID        string
+	Name      string
+	UnitPrice Money
+}
+
+var ErrInvalidListFilter = errors.New("invalid list filter")
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+type ListOrdersFilter struct {
+	UserID       string
+	Status       OrderStatus
+	CreatedAfter time.Time
+	Limit        int
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+}
+
+type OrderPage struct {
+	Orders []Order
+	// NextCursor is empty on the last page.
+	NextCursor string
 }
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, userID string, items []OrderItem) (Order, error)
+	// CreateOrderWithID creates an order under an ID chosen by the caller.
+	// When that order is already saved it is returned as is. Otherwise the
+	// saga runs, and since every step is idempotent per order ID, repeating
+	// a call that was cut short finishes what the first one started.
+	CreateOrderWithID(ctx context.Context, orderID, userID string, items []OrderItem) (Order, error)
 	GetOrder(ctx context.Context, id string) (Order, error)
+	CancelOrder(ctx context.Context, id, reason string) (Order, error)
+	ListOrders(ctx context.Context, filter ListOrdersFilter) (OrderPage, error)
 }
 
 type InventoryClient interface {
-	ReserveStock(ctx context.Context, productID string, qty int32) error
+	ReserveStock(ctx context.Context, orderID string, items []OrderItem) error
+	CommitStock(ctx context.Context, orderID string) error
+	ReleaseStock(ctx context.Context, orderID string) error
 }
 
+// PaymentClient charges and refunds orders. ProcessPayment charges an order
+// at most once, returning the same transaction when repeated. RefundPayment
+// with an empty transactionID refunds whatever the order was charged and
+// keeps it from being charged later.
 type PaymentClient interface {
-	ProcessPayment(ctx context.Context, orderID, userID string, amount float64, method string) error
+	ProcessPayment(ctx context.Context, orderID, userID string, amount Money, method string) (string, error)
+	RefundPayment(ctx context.Context, orderID, transactionID string) error
+}
+
+type Catalog interface {
+	GetProducts(ctx context.Context, ids []string) (map[string]Product, error)
 }
 
 type OrderRepository interface {
 	SaveOrder(ctx context.Context, order Order) error
 	GetOrderByID(ctx context.Context, id string) (Order, error)
+	// UpdateStatus persists change for the order. It fails with
+	// ErrStatusConflict when the stored status is no longer change.From.
+	UpdateStatus(ctx context.Context, order Order, change StatusChange) error
+	// GetQuote returns the quote stored for orderID; found is false when
+	// there is none.
+	GetQuote(ctx context.Context, orderID string) (quote Quote, found bool, err error)
+	// SaveQuote stores quote for orderID unless one is stored already, and
+	// returns the stored quote.
+	SaveQuote(ctx context.Context, orderID string, quote Quote) (Quote, error)
+	// ListOrders returns up to limit orders matching filter, newest first,
+	// starting strictly after the after cursor when it is set.
+	ListOrders(ctx context.Context, filter ListOrdersFilter, after *OrderCursor, limit int) ([]Order, error)
 }
 
 type orderService struct {
 	inventory InventoryClient
 	payment   PaymentClient
 	repo      OrderRepository
+	catalog   Catalog
+	ids       IDGenerator
+	now       func() time.Time
 }
 
-func NewOrderService(inv InventoryClient, pay PaymentClient, repo OrderRepository) *orderService {
+func NewOrderService(inv InventoryClient, pay PaymentClient, repo OrderRepository, catalog Catalog, ids IDGenerator) *orderService {
 	return &orderService{
 		inventory: inv,
 		payment:   pay,
 		repo:      repo,
+		catalog:   catalog,
+		ids:       ids,
+		now:       time.Now,
 	}
 }
 
 func (s *orderService) CreateOrder(ctx context.Context, userID string, items []OrderItem) (Order, error) {
+	return s.create(ctx, "", userID, items)
+}
+
+func (s *orderService) CreateOrderWithID(ctx context.Context, orderID, userID string, items []OrderItem) (Order, error) {
+	if orderID == "" {
+		return Order{}, fmt.Errorf("%w: orderID cannot be empty", ErrInvalidOrder)
+	}
+	return s.create(ctx, orderID, userID, items)
+}
+
+// create traces createOrder; an empty orderID gets a new one.
+func (s *orderService) create(ctx context.Context, orderID, userID string, items []OrderItem) (Order, error) {
+	ctx = logging.WithUserID(ctx, userID)
+	ctx, span := tracer.Start(ctx, "orderService.CreateOrder",
+		trace.WithAttributes(attribute.String("order.user_id", userID), attribute.Int("order.items", len(items))))
+	order, err := s.createOrder(ctx, orderID, userID, items)
+	if err == nil {
+		span.SetAttributes(attribute.String("order.id", order.ID), attribute.String("order.status", string(order.Status)))
+		slog.InfoContext(logging.WithOrderID(ctx, order.ID), "order created",
+			"status", order.Status, "amount", order.Total.Amount, "currency", order.Total.Currency)
+	}
+	endSpan(span, err)
+	return order, err
+}
+
+func (s *orderService) createOrder(ctx context.Context, orderID, userID string, items []OrderItem) (Order, error) {
 	if userID == "" {
-		return Order{}, errors.New("userID cannot be empty")
+		return Order{}, fmt.Errorf("%w: userID cannot be empty", ErrInvalidOrder)
 	}
 	if len(items) == 0 {
-		return Order{}, errors.New("items cannot be empty")
+		return Order{}, fmt.Errorf("%w: items cannot be empty", ErrInvalidOrder)
 	}
 
-	first := items[0]
-	if err := s.inventory.ReserveStock(ctx, first.ProductID, int32(first.Quantity)); err != nil {
-		return Order{}, err
+	for _, it := range items {
+		if it.ProductID == "" {
+			return Order{}, fmt.Errorf("%w: productID cannot be empty", ErrInvalidOrder)
+		}
+		if it.Quantity <= 0 {
+			return Order{}, fmt.Errorf("%w: quantity must be greater than 0", ErrInvalidOrder)
+		}
 	}
 
-	order := Order{
-		ID:     "order-124",
-		UserID: userID,
-		Status: "paid",
-		Items:  items,
+	if orderID != "" {
+		saved, err := s.repo.GetOrderByID(ctx, orderID)
+		if err == nil {
+			return saved, nil
+		}
+		if !errors.Is(err, ErrOrderNotFound) {
+			return Order{}, fmt.Errorf("load order: %w", err)
+		}
 	}
 
-	if err := s.payment.ProcessPayment(ctx, order.ID, userID, 100.0, "card"); err != nil {
+	var total Money
+	var err error
+	if orderID == "" {
+		if items, total, err = s.price(ctx, items); err != nil {
+			return Order{}, err
+		}
+		if orderID, err = s.ids.NewID(); err != nil {
+			return Order{}, fmt.Errorf("generate order id: %w", err)
+		}
+	} else if items, total, err = s.quote(ctx, orderID, items); err != nil {
 		return Order{}, err
 	}
+	// Every log line and gRPC call of the saga carries the new order's ID.
+	ctx = logging.WithOrderID(ctx, orderID)
+
+	createdAt := s.now().UTC()
+	order := Order{
+		ID:        orderID,
+		UserID:    userID,
+		Status:    StatusPending,
+		Items:     items,
+		Total:     total,
+		CreatedAt: createdAt,
+		History: []StatusChange{
+			{To: StatusPending, Reason: "order created", At: createdAt},
+		},
+	}
 
-	if err := s.repo.SaveOrder(ctx, order); err != nil {
+	var transactionID string
+
+	sg := newSaga("create order " + order.ID)
+	sg.step("reserve stock",
+		func(ctx context.Context) error {
+			if err := s.inventory.ReserveStock(ctx, order.ID, items); err != nil {
+				return err
+			}
+			return order.Transition(StatusReserved, "stock reserved", s.now().UTC())
+		},
+		func(ctx context.Context) error {
+			return s.inventory.ReleaseStock(ctx, order.ID)
+		},
+	)
+	// A payment that failed with anything but a rejection, such as a
+	// timeout, may still have been charged. Its compensation then refunds by
+	// order, with no transaction ID, which also voids a charge still on its
+	// way to the payment service.
+	sg.uncertainStep("process payment",
+		func(ctx context.Context) error {
+			txID, err := s.payment.ProcessPayment(ctx, order.ID, userID, order.Total, "card")
+			if err != nil {
+				return err
+			}
+			transactionID = txID
+			order.PaymentID = txID
+			return order.Transition(StatusPaid, "payment "+txID+" processed", s.now().UTC())
+		},
+		func(ctx context.Context) error {
+			return s.payment.RefundPayment(ctx, order.ID, transactionID)
+		},
+		func(err error) bool {
+			return errors.Is(err, ErrPaymentRejected)
+		},
+	)
+	// The order is saved before the stock is committed, since a committed
+	// reservation without an order would never be released. Saving also
+	// stores the OrderPaid event, on which inventory commits the
+	// reservation too, so a crash before "commit stock" does not leave it
+	// to expire. A failed save may still have been stored; its compensation
+	// cancels the order, and a status conflict means there was none.
+	sg.uncertainStep("save order",
+		func(ctx context.Context) error {
+			return s.repo.SaveOrder(ctx, order)
+		},
+		func(ctx context.Context) error {
+			if err := order.Transition(StatusCancelled, "order creation failed", s.now().UTC()); err != nil {
+				return err
+			}
+			err := s.repo.UpdateStatus(ctx, order, order.History[len(order.History)-1])
+			if errors.Is(err, ErrStatusConflict) {
+				return nil
+			}
+			return err
+		},
+		func(err error) bool {
+			return false
+		},
+	)
+	// Releasing a committed reservation returns the stock as well, so
+	// "reserve stock" compensation covers this step.
+	sg.step("commit stock",
+		func(ctx context.Context) error {
+			return s.inventory.CommitStock(ctx, order.ID)
+		},
+		nil,
+	)
+
+	if err := sg.run(ctx); err != nil {
+		ordersCreated.WithLabelValues(string(StatusFailed)).Inc()
 		return Order{}, err
 	}
 
+	ordersCreated.WithLabelValues(string(order.Status)).Inc()
 	return order, nil
 }
 
+// quote prices items for an order whose ID may be repeated. The first call
+// stores the prices and later ones reuse them, so a repeated order charges
+// the amount of the first attempt even if the catalog changed in between:
+// the payment service does not charge one order two different amounts.
+func (s *orderService) quote(ctx context.Context, orderID string, items []OrderItem) ([]OrderItem, Money, error) {
+	stored, found, err := s.repo.GetQuote(ctx, orderID)
+	if err != nil {
+		return nil, Money{}, fmt.Errorf("load quote: %w", err)
+	}
+	if found {
+		return stored.Items, stored.Total, nil
+	}
+
+	items, total, err := s.price(ctx, items)
+	if err != nil {
+		return nil, Money{}, err
+	}
+	stored, err = s.repo.SaveQuote(ctx, orderID, Quote{Items: items, Total: total})
+	if err != nil {
+		return nil, Money{}, fmt.Errorf("save quote: %w", err)
+	}
+	return stored.Items, stored.Total, nil
+}
+
+// price returns a copy of items with catalog unit prices filled in, along
+// with the order total. All lines of an order must share one currency.
+func (s *orderService) price(ctx context.Context, items []OrderItem) ([]OrderItem, Money, error) {
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.ProductID
+	}
+
+	products, err := s.catalog.GetProducts(ctx, ids)
+	if err != nil {
+		return nil, Money{}, fmt.Errorf("load catalog: %w", err)
+	}
+
+	priced := make([]OrderItem, len(items))
+	var total Money
+	for i, it := range items {
+		p, ok := products[it.ProductID]
+		if !ok {
+			return nil, Money{}, fmt.Errorf("%w: %q", ErrUnknownProduct, it.ProductID)
+		}
+		if err := p.UnitPrice.Validate(); err != nil {
+			return nil, Money{}, fmt.Errorf("product %q: %w", p.ID, err)
+		}
+		if i == 0 {
+			total = Money{Currency: p.UnitPrice.Currency}
+		}
+
+		it.UnitPrice = p.UnitPrice
+		priced[i] = it
+		line, err := it.LineTotal()
+		if err == nil {
+			total, err = total.Add(line)
+		}
+		if errors.Is(err, ErrAmountOverflow) {
+			return nil, Money{}, fmt.Errorf("%w: product %q: %w", ErrInvalidOrder, p.ID, err)
+		}
+		if err != nil {
+			return nil, Money{}, fmt.Errorf("product %q: %w", p.ID, err)
+		}
+	}
+
+	return priced, total, nil
+}
+
 func (s *orderService) GetOrder(ctx context.Context, id string) (Order, error) {
 	return s.repo.GetOrderByID(ctx, id)
 }
+
+// CancelOrder releases the order's stock, refunds its payment when it was
+// paid and moves it to cancelled. Both compensations are idempotent, so a
+// failed cancellation can simply be retried.
+func (s *orderService) CancelOrder(ctx context.Context, id, reason string) (Order, error) {
+	ctx = logging.WithOrderID(ctx, id)
+	ctx, span := tracer.Start(ctx, "orderService.CancelOrder",
+		trace.WithAttributes(attribute.String("order.id", id)))
+	order, err := s.cancelOrder(ctx, id, reason)
+	endSpan(span, err)
+	return order, err
+}
+
+func (s *orderService) cancelOrder(ctx context.Context, id, reason string) (Order, error) {
+	order, err := s.repo.GetOrderByID(ctx, id)
+	if err != nil {
+		return Order{}, err
+	}
+	ctx = logging.WithUserID(ctx, order.UserID)
+
+	if !order.Status.CanTransitionTo(StatusCancelled) {
+		return Order{}, fmt.Errorf("%w: order in status %s cannot be cancelled", ErrInvalidTransition, order.Status)
+	}
+	if reason == "" {
+		reason = "cancelled by request"
+	}
+
+	if err := s.inventory.ReleaseStock(ctx, order.ID); err != nil {
+		return Order{}, fmt.Errorf("release stock: %w", err)
+	}
+	if order.PaymentID != "" {
+		if err := s.payment.RefundPayment(ctx, order.ID, order.PaymentID); err != nil {
+			return Order{}, fmt.Errorf("refund payment: %w", err)
+		}
+	}
+
+	if err := order.Transition(StatusCancelled, reason, s.now().UTC()); err != nil {
+		return Order{}, err
+	}
+	if err := s.repo.UpdateStatus(ctx, order, order.History[len(order.History)-1]); err != nil {
+		return Order{}, err
+	}
+
+	slog.InfoContext(ctx, "order cancelled", "reason", reason)
+	return order, nil
+}
+
+func (s *orderService) ListOrders(ctx context.Context, filter ListOrdersFilter) (OrderPage, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	if limit < 0 || limit > MaxListLimit {
+		return OrderPage{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListFilter, MaxListLimit)
+	}
+	if filter.Status != "" && !filter.Status.Valid() {
+		return OrderPage{}, fmt.Errorf("%w: unknown order status %q", ErrInvalidListFilter, filter.Status)
+	}
+
+	var after *OrderCursor
+	if filter.Cursor != "" {
+		c, err := DecodeOrderCursor(filter.Cursor)
+		if err != nil {
+			return OrderPage{}, err
+		}
+		after = &c
+	}
+
+	// One extra row tells whether there is a next page.
+	orders, err := s.repo.ListOrders(ctx, filter, after, limit+1)
+	if err != nil {
+		return OrderPage{}, err
+	}
+
+	page := OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return page, nil
+}
@@ -3,23 +3,51 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"testing"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type mockRepo struct {
-	saveErr  error
-	getErr   error
-	getOrder Order
+	saveErr   error
+	getErr    error
+	updateErr error
+	getOrder  Order
 
 	saveCalled bool
 	savedOrder Order
+
+	updateCalled  bool
+	updatedID     string
+	updatedChange StatusChange
+
+	quotes map[string]Quote
+
+	listOrders []Order
+	listFilter ListOrdersFilter
+	listAfter  *OrderCursor
+	listLimit  int
 }
 type mockInventoryClient struct {
 	reserveErr error
+	commitErr  error
+	releaseErr error
 
-	called       bool
-	calledProdID string
-	calledQty    int32
+	called      bool
+	calledOrder string
+	calledItems []OrderItem
+	calledCtx   context.Context
+
+	committed bool
+	released  bool
 }
 
 func (m *mockRepo) SaveOrder(ctx context.Context, order Order) error {
@@ -35,30 +63,114 @@ func (m *mockRepo) GetOrderByID(ctx context.Context, id string) (Order, error) {
 	return m.getOrder, nil
 }
 
-func (m *mockInventoryClient) ReserveStock(ctx context.Context, productID string, qty int32) error {
+func (m *mockRepo) UpdateStatus(ctx context.Context, order Order, change StatusChange) error {
+	m.updateCalled = true
+	m.updatedID = order.ID
+	m.updatedChange = change
+	return m.updateErr
+}
+
+func (m *mockRepo) GetQuote(ctx context.Context, orderID string) (Quote, bool, error) {
+	quote, ok := m.quotes[orderID]
+	return quote, ok, nil
+}
+
+func (m *mockRepo) SaveQuote(ctx context.Context, orderID string, quote Quote) (Quote, error) {
+	if stored, ok := m.quotes[orderID]; ok {
+		return stored, nil
+	}
+	if m.quotes == nil {
+		m.quotes = make(map[string]Quote)
+	}
+	m.quotes[orderID] = quote
+	return quote, nil
+}
+
+func (m *mockRepo) ListOrders(ctx context.Context, filter ListOrdersFilter, after *OrderCursor, limit int) ([]Order, error) {
+	m.listFilter = filter
+	m.listAfter = after
+	m.listLimit = limit
+	if len(m.listOrders) > limit {
+		return m.listOrders[:limit], nil
+	}
+	return m.listOrders, nil
+}
+
+func (m *mockInventoryClient) ReserveStock(ctx context.Context, orderID string, items []OrderItem) error {
 	m.called = true
-	m.calledProdID = productID
-	m.calledQty = qty
+	m.calledOrder = orderID
+	m.calledItems = items
+	m.calledCtx = ctx
 	return m.reserveErr
 }
 
+func (m *mockInventoryClient) CommitStock(ctx context.Context, orderID string) error {
+	m.committed = true
+	return m.commitErr
+}
+
+func (m *mockInventoryClient) ReleaseStock(ctx context.Context, orderID string) error {
+	m.released = true
+	return m.releaseErr
+}
+
 type mockPaymentClient struct {
-	payErr error
+	payErr    error
+	refundErr error
 
 	called       bool
 	calledOrder  string
 	calledUser   string
-	calledAmt    float64
+	calledAmt    Money
 	calledMethod string
+
+	refunded   bool
+	refundedTx string
 }
 
-func (m *mockPaymentClient) ProcessPayment(ctx context.Context, orderID, userID string, amount float64, method string) error {
+func (m *mockPaymentClient) ProcessPayment(ctx context.Context, orderID, userID string, amount Money, method string) (string, error) {
 	m.called = true
 	m.calledOrder = orderID
 	m.calledUser = userID
 	m.calledAmt = amount
 	m.calledMethod = method
-	return m.payErr
+	if m.payErr != nil {
+		return "", m.payErr
+	}
+	return "tx_1", nil
+}
+
+func (m *mockPaymentClient) RefundPayment(ctx context.Context, orderID, transactionID string) error {
+	m.refunded = true
+	m.refundedTx = transactionID
+	return m.refundErr
+}
+
+type mockCatalog struct {
+	products map[string]Product
+	err      error
+}
+
+func (m *mockCatalog) GetProducts(ctx context.Context, ids []string) (map[string]Product, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.products, nil
+}
+
+func newMockCatalog() *mockCatalog {
+	return &mockCatalog{products: map[string]Product{
+		"p1": {ID: "p1", Name: "Keyboard", UnitPrice: Money{Amount: 1050, Currency: "RUB"}},
+		"p2": {ID: "p2", Name: "Mouse", UnitPrice: Money{Amount: 225, Currency: "RUB"}},
+	}}
+}
+
+type staticIDs struct {
+	id string
+}
+
+func (g staticIDs) NewID() (string, error) {
+	return g.id, nil
 }
 
 func TestCreateOrder_Success(t *testing.T) {
@@ -68,7 +180,7 @@ func TestCreateOrder_Success(t *testing.T) {
 	payMock := &mockPaymentClient{}
 	repoMock := &mockRepo{}
 
-	svc := NewOrderService(invMock, payMock, repoMock)
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
 
 	items := []OrderItem{{ProductID: "p1", Quantity: 2}}
 
@@ -80,9 +192,17 @@ func TestCreateOrder_Success(t *testing.T) {
 	if !invMock.called {
 		t.Errorf("expected inventory.ReserveStock to be called")
 	}
-	if invMock.calledProdID != "p1" || invMock.calledQty != 2 {
-		t.Errorf("inventory called with wrong args: product=%v qty=%v",
-			invMock.calledProdID, invMock.calledQty)
+	if len(invMock.calledItems) != 1 || invMock.calledItems[0].ProductID != "p1" || invMock.calledItems[0].Quantity != 2 {
+		t.Errorf("inventory called with wrong args: %+v", invMock.calledItems)
+	}
+	if invMock.calledOrder != order.ID {
+		t.Errorf("reservation tied to wrong order: %v", invMock.calledOrder)
+	}
+	if !invMock.committed {
+		t.Errorf("expected inventory.CommitStock to be called")
+	}
+	if invMock.released {
+		t.Errorf("expected inventory.ReleaseStock NOT to be called")
 	}
 
 	if !payMock.called {
@@ -102,22 +222,85 @@ func TestCreateOrder_Success(t *testing.T) {
 	if order.UserID != "u1" {
 		t.Errorf("order UserID is wrong: %v", order.UserID)
 	}
-	if order.Status != "paid" {
+	if order.Status != StatusPaid {
 		t.Errorf("order Status is wrong: %v", order.Status)
 	}
+
+	if repoMock.savedOrder.PaymentID != "tx_1" {
+		t.Errorf("payment transaction not saved: %v", repoMock.savedOrder.PaymentID)
+	}
+
+	wantHistory := []OrderStatus{StatusPending, StatusReserved, StatusPaid}
+	if len(repoMock.savedOrder.History) != len(wantHistory) {
+		t.Fatalf("saved history is wrong: %+v", repoMock.savedOrder.History)
+	}
+	for i, change := range repoMock.savedOrder.History {
+		if change.To != wantHistory[i] {
+			t.Errorf("history[%d] = %v, want %v", i, change.To, wantHistory[i])
+		}
+	}
+}
+func TestCreateOrder_ReservesAllItems(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	items := []OrderItem{
+		{ProductID: "p1", Quantity: 2},
+		{ProductID: "p2", Quantity: 3},
+	}
+
+	if _, err := svc.CreateOrder(ctx, "u1", items); err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+
+	if len(invMock.calledItems) != 2 {
+		t.Fatalf("expected 2 items to be reserved, got %d", len(invMock.calledItems))
+	}
+	for i, it := range items {
+		if invMock.calledItems[i].ProductID != it.ProductID || invMock.calledItems[i].Quantity != it.Quantity {
+			t.Errorf("item %d reserved with wrong args: %+v", i, invMock.calledItems[i])
+		}
+	}
+}
+
+func TestCreateOrder_InvalidQuantity(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{
+		{ProductID: "p1", Quantity: 1},
+		{ProductID: "p2", Quantity: 0},
+	})
+	if !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder, got %v", err)
+	}
+	if invMock.called {
+		t.Errorf("expected inventory.ReserveStock NOT to be called")
+	}
 }
+
 func TestCreateOrder_InventoryError(t *testing.T) {
 	ctx := context.Background()
 
-	invMock := &mockInventoryClient{reserveErr: errors.New("no stock")}
+	invMock := &mockInventoryClient{reserveErr: fmt.Errorf("%w: not enough stock", ErrInsufficientStock)}
 	payMock := &mockPaymentClient{}
 	repoMock := &mockRepo{}
 
-	svc := NewOrderService(invMock, payMock, repoMock)
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
 
 	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
-	if err == nil {
-		t.Fatalf("expected error, got nil")
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("expected ErrInsufficientStock through the saga error, got %v", err)
 	}
 
 	if !invMock.called {
@@ -129,16 +312,19 @@ func TestCreateOrder_InventoryError(t *testing.T) {
 	if repoMock.saveCalled {
 		t.Errorf("expected repo.SaveOrder NOT to be called")
 	}
+	if invMock.released {
+		t.Errorf("expected inventory.ReleaseStock NOT to be called")
+	}
 }
 
 func TestCreateOrder_PaymentError(t *testing.T) {
 	ctx := context.Background()
 
 	invMock := &mockInventoryClient{}
-	payMock := &mockPaymentClient{payErr: errors.New("payment failed")}
+	payMock := &mockPaymentClient{payErr: fmt.Errorf("%w: card declined", ErrPaymentRejected)}
 	repoMock := &mockRepo{}
 
-	svc := NewOrderService(invMock, payMock, repoMock)
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
 
 	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
 	if err == nil {
@@ -154,4 +340,588 @@ func TestCreateOrder_PaymentError(t *testing.T) {
 	if repoMock.saveCalled {
 		t.Errorf("expected repo.SaveOrder NOT to be called")
 	}
+	if !invMock.released {
+		t.Errorf("expected inventory.ReleaseStock to be called")
+	}
+	if invMock.committed {
+		t.Errorf("expected inventory.CommitStock NOT to be called")
+	}
+	if payMock.refunded {
+		t.Errorf("expected payment NOT to be refunded")
+	}
+}
+
+func TestCreateOrder_UncertainPaymentIsRefunded(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{payErr: fmt.Errorf("%w: deadline exceeded", ErrUnavailable)}
+	svc := NewOrderService(invMock, payMock, &mockRepo{}, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
+	var sagaErr *SagaError
+	if !errors.As(err, &sagaErr) {
+		t.Fatalf("expected *SagaError, got %v", err)
+	}
+
+	if !payMock.refunded || payMock.refundedTx != "" {
+		t.Errorf("expected a refund by order, got refunded=%v tx=%q", payMock.refunded, payMock.refundedTx)
+	}
+	if !invMock.released {
+		t.Errorf("expected reservation to be released")
+	}
+	want := []StepOutcome{
+		{Step: "reserve stock", Status: StepDone},
+		{Step: "process payment", Status: StepFailed},
+		{Step: "process payment", Status: StepCompensated},
+		{Step: "reserve stock", Status: StepCompensated},
+	}
+	if len(sagaErr.Outcomes) != len(want) {
+		t.Fatalf("unexpected outcomes: %+v", sagaErr.Outcomes)
+	}
+	for i, o := range sagaErr.Outcomes {
+		if o.Step != want[i].Step || o.Status != want[i].Status {
+			t.Errorf("outcome %d = %s/%s, want %s/%s", i, o.Step, o.Status, want[i].Step, want[i].Status)
+		}
+	}
+}
+
+func TestCreateOrder_SaveErrorCompensatesBeforeCommit(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	// Nothing was stored, so the order cannot be cancelled either.
+	repoMock := &mockRepo{saveErr: errors.New("db down"), updateErr: ErrStatusConflict}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	items := []OrderItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p2", Quantity: 2}}
+	_, err := svc.CreateOrder(ctx, "u1", items)
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if !payMock.refunded || payMock.refundedTx != "tx_1" {
+		t.Errorf("expected payment tx_1 to be refunded, got refunded=%v tx=%v", payMock.refunded, payMock.refundedTx)
+	}
+	if !invMock.released {
+		t.Errorf("expected reservation to be released")
+	}
+	if invMock.committed {
+		t.Errorf("expected inventory.CommitStock NOT to be called for an unsaved order")
+	}
+
+	assertOutcomes(t, err, []StepOutcome{
+		{Step: "reserve stock", Status: StepDone},
+		{Step: "process payment", Status: StepDone},
+		{Step: "save order", Status: StepFailed},
+		{Step: "save order", Status: StepCompensated},
+		{Step: "process payment", Status: StepCompensated},
+		{Step: "reserve stock", Status: StepCompensated},
+	})
+}
+
+func TestCreateOrder_CommitErrorCancelsSavedOrder(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{commitErr: fmt.Errorf("%w: inventory down", ErrUnavailable)}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+
+	if !repoMock.saveCalled {
+		t.Fatalf("expected the order to be saved before the stock is committed")
+	}
+	if repoMock.updatedID != "order-124" || repoMock.updatedChange.From != StatusPaid || repoMock.updatedChange.To != StatusCancelled {
+		t.Errorf("expected saved order to be cancelled, got %s %+v", repoMock.updatedID, repoMock.updatedChange)
+	}
+	if !payMock.refunded || payMock.refundedTx != "tx_1" {
+		t.Errorf("expected payment tx_1 to be refunded, got refunded=%v tx=%v", payMock.refunded, payMock.refundedTx)
+	}
+	if !invMock.released {
+		t.Errorf("expected reservation to be released")
+	}
+
+	assertOutcomes(t, err, []StepOutcome{
+		{Step: "reserve stock", Status: StepDone},
+		{Step: "process payment", Status: StepDone},
+		{Step: "save order", Status: StepDone},
+		{Step: "commit stock", Status: StepFailed},
+		{Step: "save order", Status: StepCompensated},
+		{Step: "process payment", Status: StepCompensated},
+		{Step: "reserve stock", Status: StepCompensated},
+	})
+}
+
+func assertOutcomes(t *testing.T, err error, want []StepOutcome) {
+	t.Helper()
+	var sagaErr *SagaError
+	if !errors.As(err, &sagaErr) {
+		t.Fatalf("expected *SagaError, got %T", err)
+	}
+	if len(sagaErr.Outcomes) != len(want) {
+		t.Fatalf("unexpected outcomes: %+v", sagaErr.Outcomes)
+	}
+	for i, o := range sagaErr.Outcomes {
+		if o.Step != want[i].Step || o.Status != want[i].Status {
+			t.Errorf("outcome %d = %s/%s, want %s/%s", i, o.Step, o.Status, want[i].Step, want[i].Status)
+		}
+	}
+}
+
+func TestCreateOrder_CompensationFailureIsReported(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{releaseErr: errors.New("inventory down")}
+	payMock := &mockPaymentClient{payErr: errors.New("payment failed")}
+	repoMock := &mockRepo{}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
+
+	var sagaErr *SagaError
+	if !errors.As(err, &sagaErr) {
+		t.Fatalf("expected *SagaError, got %v", err)
+	}
+	if sagaErr.Step != "process payment" {
+		t.Errorf("failed step is wrong: %v", sagaErr.Step)
+	}
+	last := sagaErr.Outcomes[len(sagaErr.Outcomes)-1]
+	if last.Step != "reserve stock" || last.Status != StepCompensationFailed {
+		t.Errorf("expected failed compensation to be recorded, got %+v", last)
+	}
+}
+
+func TestUUIDGenerator_UniqueAndSortable(t *testing.T) {
+	var gen UUIDGenerator
+
+	prev := ""
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := gen.NewID()
+		if err != nil {
+			t.Fatalf("NewID failed: %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %v", id)
+		}
+		if id <= prev {
+			t.Errorf("ids are not time-sortable: %v after %v", id, prev)
+		}
+		seen[id] = true
+		prev = id
+	}
+}
+
+func TestCreateOrder_ChargesCatalogTotal(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	order, err := svc.CreateOrder(ctx, "u1", []OrderItem{
+		{ProductID: "p1", Quantity: 2},
+		{ProductID: "p2", Quantity: 4},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+
+	want := Money{Amount: 3000, Currency: "RUB"}
+	if order.Total != want {
+		t.Errorf("order total is wrong: %v", order.Total)
+	}
+	if payMock.calledAmt != want {
+		t.Errorf("payment charged wrong amount: %v", payMock.calledAmt)
+	}
+	if repoMock.savedOrder.Items[0].UnitPrice.Amount != 1050 || repoMock.savedOrder.Items[1].UnitPrice.Amount != 225 {
+		t.Errorf("unit price snapshot not saved: %+v", repoMock.savedOrder.Items)
+	}
+}
+
+func TestCreateOrder_MixedCurrencies(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{}
+
+	catalog := newMockCatalog()
+	catalog.products["p3"] = Product{ID: "p3", Name: "Cable", UnitPrice: Money{Amount: 500, Currency: "USD"}}
+
+	svc := NewOrderService(invMock, payMock, repoMock, catalog, staticIDs{id: "order-124"})
+
+	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{
+		{ProductID: "p1", Quantity: 1},
+		{ProductID: "p3", Quantity: 1},
+	})
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if invMock.called {
+		t.Errorf("expected inventory.ReserveStock NOT to be called")
+	}
+}
+
+func TestCreateOrder_TotalOverflow(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	catalog := newMockCatalog()
+	catalog.products["p4"] = Product{ID: "p4", Name: "Yacht", UnitPrice: Money{Amount: math.MaxInt64 / 2, Currency: "RUB"}}
+
+	svc := NewOrderService(invMock, &mockPaymentClient{}, &mockRepo{}, catalog, staticIDs{id: "order-125"})
+
+	for _, items := range [][]OrderItem{
+		{{ProductID: "p4", Quantity: 3}},
+		{{ProductID: "p4", Quantity: 2}, {ProductID: "p1", Quantity: 1}},
+	} {
+		_, err := svc.CreateOrder(ctx, "u1", items)
+		if !errors.Is(err, ErrInvalidOrder) || !errors.Is(err, ErrAmountOverflow) {
+			t.Fatalf("expected ErrInvalidOrder and ErrAmountOverflow, got %v", err)
+		}
+	}
+	if invMock.called {
+		t.Errorf("expected inventory.ReserveStock NOT to be called")
+	}
+}
+
+func TestCreateOrder_UnknownProduct(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "nope", Quantity: 1}})
+	if !errors.Is(err, ErrUnknownProduct) {
+		t.Fatalf("expected ErrUnknownProduct, got %v", err)
+	}
+	if invMock.called {
+		t.Errorf("expected inventory.ReserveStock NOT to be called")
+	}
+}
+
+func TestCancelOrder_PaidOrderIsRefunded(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{getOrder: Order{ID: "o1", UserID: "u1", Status: StatusPaid, PaymentID: "tx_7"}}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	order, err := svc.CancelOrder(ctx, "o1", "changed my mind")
+	if err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+
+	if !invMock.released {
+		t.Errorf("expected inventory.ReleaseStock to be called")
+	}
+	if !payMock.refunded || payMock.refundedTx != "tx_7" {
+		t.Errorf("expected payment tx_7 to be refunded, got refunded=%v tx=%v", payMock.refunded, payMock.refundedTx)
+	}
+	if order.Status != StatusCancelled {
+		t.Errorf("order Status is wrong: %v", order.Status)
+	}
+	if !repoMock.updateCalled || repoMock.updatedID != "o1" {
+		t.Fatalf("expected repo.UpdateStatus to be called for o1")
+	}
+	if repoMock.updatedChange.From != StatusPaid || repoMock.updatedChange.To != StatusCancelled || repoMock.updatedChange.Reason != "changed my mind" {
+		t.Errorf("persisted change is wrong: %+v", repoMock.updatedChange)
+	}
+}
+
+func TestCancelOrder_UnpaidOrderIsNotRefunded(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{getOrder: Order{ID: "o1", UserID: "u1", Status: StatusReserved}}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	if _, err := svc.CancelOrder(ctx, "o1", ""); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	if !invMock.released {
+		t.Errorf("expected inventory.ReleaseStock to be called")
+	}
+	if payMock.refunded {
+		t.Errorf("expected payment NOT to be refunded")
+	}
+}
+
+func TestCancelOrder_ShippedOrderIsRejected(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{getOrder: Order{ID: "o1", UserID: "u1", Status: StatusShipped, PaymentID: "tx_7"}}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CancelOrder(ctx, "o1", "")
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if invMock.released || payMock.refunded || repoMock.updateCalled {
+		t.Errorf("expected no side effects for a shipped order")
+	}
+}
+
+func TestCancelOrder_MissingOrder(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	repoMock := &mockRepo{getErr: fmt.Errorf("%w: o1", ErrOrderNotFound)}
+
+	svc := NewOrderService(invMock, &mockPaymentClient{}, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	_, err := svc.CancelOrder(ctx, "o1", "")
+	if !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+	if invMock.released {
+		t.Errorf("expected no release for a missing order")
+	}
+}
+
+func TestCancelOrder_RefundErrorKeepsStatus(t *testing.T) {
+	ctx := context.Background()
+
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{refundErr: errors.New("payment down")}
+	repoMock := &mockRepo{getOrder: Order{ID: "o1", UserID: "u1", Status: StatusPaid, PaymentID: "tx_7"}}
+
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	if _, err := svc.CancelOrder(ctx, "o1", ""); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if repoMock.updateCalled {
+		t.Errorf("expected status NOT to be updated when refund fails")
+	}
+}
+
+func TestListOrders_Paginates(t *testing.T) {
+	ctx := context.Background()
+
+	base := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	repoMock := &mockRepo{listOrders: []Order{
+		{ID: "o3", CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "o2", CreatedAt: base.Add(time.Minute)},
+		{ID: "o1", CreatedAt: base},
+	}}
+
+	svc := NewOrderService(&mockInventoryClient{}, &mockPaymentClient{}, repoMock, newMockCatalog(), staticIDs{id: "order-124"})
+
+	page, err := svc.ListOrders(ctx, ListOrdersFilter{UserID: "u1", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListOrders failed: %v", err)
+	}
+
+	if repoMock.listLimit != 3 {
+		t.Errorf("expected one extra row to be requested, got limit %d", repoMock.listLimit)
+	}
+	if repoMock.listFilter.UserID != "u1" || repoMock.listAfter != nil {
+		t.Errorf("repo called with wrong args: %+v %+v", repoMock.listFilter, repoMock.listAfter)
+	}
+	if len(page.Orders) != 2 || page.Orders[1].ID != "o2" {
+		t.Fatalf("page is wrong: %+v", page.Orders)
+	}
+
+	cursor, err := DecodeOrderCursor(page.NextCursor)
+	if err != nil {
+		t.Fatalf("next cursor is invalid: %v", err)
+	}
+	if cursor.ID != "o2" || !cursor.CreatedAt.Equal(base.Add(time.Minute)) {
+		t.Errorf("next cursor points at the wrong order: %+v", cursor)
+	}
+
+	repoMock.listOrders = repoMock.listOrders[2:]
+	page, err = svc.ListOrders(ctx, ListOrdersFilter{UserID: "u1", Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("ListOrders failed: %v", err)
+	}
+	if repoMock.listAfter == nil || repoMock.listAfter.ID != "o2" {
+		t.Errorf("cursor not passed to repo: %+v", repoMock.listAfter)
+	}
+	if len(page.Orders) != 1 || page.NextCursor != "" {
+		t.Errorf("last page is wrong: %+v next=%q", page.Orders, page.NextCursor)
+	}
+}
+
+func TestListOrders_InvalidFilter(t *testing.T) {
+	ctx := context.Background()
+
+	svc := NewOrderService(&mockInventoryClient{}, &mockPaymentClient{}, &mockRepo{}, newMockCatalog(), staticIDs{id: "order-124"})
+
+	cases := []struct {
+		name   string
+		filter ListOrdersFilter
+		want   error
+	}{
+		{"limit too big", ListOrdersFilter{Limit: MaxListLimit + 1}, ErrInvalidListFilter},
+		{"negative limit", ListOrdersFilter{Limit: -1}, ErrInvalidListFilter},
+		{"unknown status", ListOrdersFilter{Status: "lost"}, ErrInvalidListFilter},
+		{"garbage cursor", ListOrdersFilter{Cursor: "???"}, ErrInvalidCursor},
+	}
+	for _, c := range cases {
+		if _, err := svc.ListOrders(ctx, c.filter); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestCreateOrder_CountsOrdersByStatus(t *testing.T) {
+	ctx := context.Background()
+	paid := testutil.ToFloat64(ordersCreated.WithLabelValues(string(StatusPaid)))
+	failed := testutil.ToFloat64(ordersCreated.WithLabelValues(string(StatusFailed)))
+
+	svc := NewOrderService(&mockInventoryClient{}, &mockPaymentClient{}, &mockRepo{}, newMockCatalog(), staticIDs{id: "order-1"})
+	if _, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}}); err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+
+	svc = NewOrderService(&mockInventoryClient{}, &mockPaymentClient{payErr: errors.New("declined")}, &mockRepo{}, newMockCatalog(), staticIDs{id: "order-2"})
+	if _, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}}); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if _, err := svc.CreateOrder(ctx, "", []OrderItem{{ProductID: "p1", Quantity: 1}}); err == nil {
+		t.Fatalf("expected validation error, got nil")
+	}
+
+	if got := testutil.ToFloat64(ordersCreated.WithLabelValues(string(StatusPaid))) - paid; got != 1 {
+		t.Errorf("expected 1 paid order, got %v", got)
+	}
+	if got := testutil.ToFloat64(ordersCreated.WithLabelValues(string(StatusFailed))) - failed; got != 1 {
+		t.Errorf("expected 1 failed order, got %v", got)
+	}
+}
+
+func TestCreateOrder_TracesSagaSteps(t *testing.T) {
+	// The package tracer delegates to the first provider installed, so this
+	// is the only test that sets one.
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	payMock := &mockPaymentClient{payErr: fmt.Errorf("%w: declined", ErrPaymentRejected)}
+	svc := NewOrderService(&mockInventoryClient{}, payMock, &mockRepo{}, newMockCatalog(), staticIDs{id: "order-1"})
+	if _, err := svc.CreateOrder(context.Background(), "u1", []OrderItem{{ProductID: "p1", Quantity: 1}}); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	spans := recorder.Ended()
+	want := []string{
+		"saga step reserve stock",
+		"saga step process payment",
+		"saga compensate reserve stock",
+		"orderService.CreateOrder",
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("expected %d spans, got %d", len(want), len(spans))
+	}
+	root := spans[len(spans)-1]
+	for i, span := range spans {
+		if span.Name() != want[i] {
+			t.Errorf("span[%d] = %q, want %q", i, span.Name(), want[i])
+		}
+		if i < len(spans)-1 && span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("span %q is not a child of CreateOrder", span.Name())
+		}
+	}
+	if root.Status().Code != otelcodes.Error {
+		t.Errorf("expected CreateOrder span to be marked as failed")
+	}
+	if spans[1].Status().Code != otelcodes.Error || spans[0].Status().Code == otelcodes.Error {
+		t.Errorf("expected only the payment step to be marked as failed")
+	}
+}
+
+func TestCreateOrder_PassesCorrelationIDsToClients(t *testing.T) {
+	invMock := &mockInventoryClient{}
+	svc := NewOrderService(invMock, &mockPaymentClient{}, &mockRepo{}, newMockCatalog(), staticIDs{id: "order-1"})
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	if _, err := svc.CreateOrder(ctx, "u1", []OrderItem{{ProductID: "p1", Quantity: 1}}); err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+
+	got := invMock.calledCtx
+	if logging.RequestID(got) != "req-1" || logging.OrderID(got) != "order-1" || logging.UserID(got) != "u1" {
+		t.Errorf("inventory called without correlation IDs: request=%q order=%q user=%q",
+			logging.RequestID(got), logging.OrderID(got), logging.UserID(got))
+	}
+}
+
+func TestCreateOrderWithID_ReturnsSavedOrder(t *testing.T) {
+	saved := Order{ID: "order-1", UserID: "u1", Status: StatusPaid}
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	svc := NewOrderService(invMock, payMock, &mockRepo{getOrder: saved}, newMockCatalog(), staticIDs{id: "order-2"})
+
+	order, err := svc.CreateOrderWithID(context.Background(), "order-1", "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
+	if err != nil {
+		t.Fatalf("CreateOrderWithID failed: %v", err)
+	}
+	if order.ID != "order-1" || order.Status != StatusPaid {
+		t.Errorf("expected the saved order, got %+v", order)
+	}
+	if invMock.called || payMock.called {
+		t.Errorf("expected no saga step to run for a saved order")
+	}
+}
+
+func TestCreateOrderWithID_RunsSagaUnderGivenID(t *testing.T) {
+	invMock := &mockInventoryClient{}
+	payMock := &mockPaymentClient{}
+	repoMock := &mockRepo{getErr: ErrOrderNotFound}
+	svc := NewOrderService(invMock, payMock, repoMock, newMockCatalog(), staticIDs{id: "order-2"})
+
+	order, err := svc.CreateOrderWithID(context.Background(), "order-1", "u1", []OrderItem{{ProductID: "p1", Quantity: 1}})
+	if err != nil {
+		t.Fatalf("CreateOrderWithID failed: %v", err)
+	}
+	if order.ID != "order-1" || payMock.calledOrder != "order-1" {
+		t.Errorf("expected order-1 to be created and charged, got order %q charged %q", order.ID, payMock.calledOrder)
+	}
+}
+
+func TestCreateOrderWithID_RepeatChargesFirstQuote(t *testing.T) {
+	payMock := &mockPaymentClient{payErr: fmt.Errorf("%w: payment timed out", ErrUnavailable)}
+	repoMock := &mockRepo{getErr: ErrOrderNotFound}
+	catalog := newMockCatalog()
+	svc := NewOrderService(&mockInventoryClient{}, payMock, repoMock, catalog, staticIDs{id: "order-2"})
+	items := []OrderItem{{ProductID: "p1", Quantity: 2}}
+
+	if _, err := svc.CreateOrderWithID(context.Background(), "order-1", "u1", items); err == nil {
+		t.Fatalf("expected the first attempt to fail")
+	}
+
+	catalog.products["p1"] = Product{ID: "p1", Name: "Keyboard", UnitPrice: Money{Amount: 1200, Currency: "RUB"}}
+	payMock.payErr = nil
+	order, err := svc.CreateOrderWithID(context.Background(), "order-1", "u1", items)
+	if err != nil {
+		t.Fatalf("CreateOrderWithID failed: %v", err)
+	}
+	want := Money{Amount: 2100, Currency: "RUB"}
+	if payMock.calledAmt != want || order.Total != want || order.Items[0].UnitPrice.Amount != 1050 {
+		t.Errorf("expected the repeat to charge the first quote %v, charged %v for order %+v", want, payMock.calledAmt, order)
+	}
 }
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type StepStatus string
+
+const (
+	StepDone               StepStatus = "done"
+	StepFailed             StepStatus = "failed"
+	StepCompensated        StepStatus = "compensated"
+	StepCompensationFailed StepStatus = "compensation_failed"
+)
+
+type StepOutcome struct {
+	Step   string
+	Status StepStatus
+	Err    error
+}
+
+// SagaError is returned when a saga step fails. Outcomes lists every step
+// and compensation that was executed, in order.
+type SagaError struct {
+	Step     string
+	Err      error
+	Outcomes []StepOutcome
+}
+
+func (e *SagaError) Error() string {
+	for _, o := range e.Outcomes {
+		if o.Status == StepCompensationFailed {
+			return fmt.Sprintf("saga step %s: %v (compensation %s failed: %v)", e.Step, e.Err, o.Step, o.Err)
+		}
+	}
+	return fmt.Sprintf("saga step %s: %v", e.Step, e.Err)
+}
+
+func (e *SagaError) Unwrap() error {
+	return e.Err
+}
+
+type sagaStep struct {
+	name       string
+	action     func(ctx context.Context) error
+	compensate func(ctx context.Context) error
+	// definite reports whether an error of action means it had no effect;
+	// nil treats every error as definite.
+	definite func(err error) bool
+}
+
+type saga struct {
+	name     string
+	steps    []sagaStep
+	outcomes []StepOutcome
+}
+
+func newSaga(name string) *saga {
+	return &saga{name: name}
+}
+
+func (s *saga) step(name string, action, compensate func(ctx context.Context) error) {
+	s.steps = append(s.steps, sagaStep{name: name, action: action, compensate: compensate})
+}
+
+// uncertainStep adds a step whose action may have taken effect although it
+// failed, like a remote call that timed out after the server acted. Unless
+// definite reports the error as a clean failure, the step's own
+// compensation runs as well, so it must cope with an action that never
+// happened.
+func (s *saga) uncertainStep(name string, action, compensate func(ctx context.Context) error, definite func(err error) bool) {
+	s.steps = append(s.steps, sagaStep{name: name, action: action, compensate: compensate, definite: definite})
+}
+
+// run executes the steps in order. When a step fails, the compensations of
+// the already completed steps are executed in reverse order, preceded by
+// the failed step's own when its outcome is uncertain. Compensations run on
+// a context that is not cancelled together with the request.
+func (s *saga) run(ctx context.Context) error {
+	for i, st := range s.steps {
+		if err := s.traced(ctx, "saga step "+st.name, st.action); err != nil {
+			s.record(ctx, st.name, StepFailed, err)
+			end := i
+			if st.definite != nil && !st.definite(err) {
+				end = i + 1
+			}
+			s.rollback(context.WithoutCancel(ctx), end)
+			return &SagaError{Step: st.name, Err: err, Outcomes: s.outcomes}
+		}
+		s.record(ctx, st.name, StepDone, nil)
+	}
+	return nil
+}
+
+// rollback compensates the steps before end in reverse order.
+func (s *saga) rollback(ctx context.Context, end int) {
+	for i := end - 1; i >= 0; i-- {
+		st := s.steps[i]
+		if st.compensate == nil {
+			continue
+		}
+		if err := s.traced(ctx, "saga compensate "+st.name, st.compensate); err != nil {
+			s.record(ctx, st.name, StepCompensationFailed, err)
+			continue
+		}
+		s.record(ctx, st.name, StepCompensated, nil)
+	}
+}
+
+// traced runs fn in a child span named name.
+func (s *saga) traced(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attribute.String("saga", s.name)))
+	err := fn(ctx)
+	endSpan(span, err)
+	return err
+}
+
+func (s *saga) record(ctx context.Context, step string, status StepStatus, err error) {
+	s.outcomes = append(s.outcomes, StepOutcome{Step: step, Status: status, Err: err})
+	if err != nil {
+		level := slog.LevelWarn
+		if status == StepCompensationFailed {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "saga step "+string(status), "saga", s.name, "step", step, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "saga step "+string(status), "saga", s.name, "step", step)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrStatusConflict    = errors.New("order status changed concurrently")
+)
+
+type OrderStatus string
+
+const (
+	StatusPending   OrderStatus = "pending"
+	StatusReserved  OrderStatus = "reserved"
+	StatusPaid      OrderStatus = "paid"
+	StatusFulfilled OrderStatus = "fulfilled"
+	StatusShipped   OrderStatus = "shipped"
+	StatusDelivered OrderStatus = "delivered"
+	StatusCancelled OrderStatus = "cancelled"
+	StatusRefunded  OrderStatus = "refunded"
+	StatusFailed    OrderStatus = "failed"
+)
+
+var transitions = map[OrderStatus][]OrderStatus{
+	StatusPending:   {StatusReserved, StatusCancelled, StatusFailed},
+	StatusReserved:  {StatusPaid, StatusCancelled, StatusFailed},
+	StatusPaid:      {StatusFulfilled, StatusShipped, StatusCancelled, StatusRefunded},
+	StatusFulfilled: {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusDelivered},
+	StatusDelivered: {StatusRefunded},
+	StatusCancelled: {StatusRefunded},
+}
+
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case StatusPending, StatusReserved, StatusPaid, StatusFulfilled, StatusShipped,
+		StatusDelivered, StatusCancelled, StatusRefunded, StatusFailed:
+		return true
+	}
+	return false
+}
+
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange is one entry of the order status history. From is empty for
+// the entry that created the order.
+type StatusChange struct {
+	From   OrderStatus
+	To     OrderStatus
+	Reason string
+	At     time.Time
+}
+
+// Transition moves the order to status to and appends the change to its
+// history. Illegal transitions are rejected and leave the order untouched.
+func (o *Order) Transition(to OrderStatus, reason string, at time.Time) error {
+	if !o.Status.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, to)
+	}
+	o.History = append(o.History, StatusChange{From: o.Status, To: to, Reason: reason, At: at})
+	o.Status = to
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrderStatus_CanTransitionTo(t *testing.T) {
+	cases := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{StatusPending, StatusReserved, true},
+		{StatusReserved, StatusPaid, true},
+		{StatusPaid, StatusFulfilled, true},
+		{StatusPaid, StatusShipped, true},
+		{StatusShipped, StatusDelivered, true},
+		{StatusPaid, StatusCancelled, true},
+		{StatusCancelled, StatusRefunded, true},
+		{StatusReserved, StatusFailed, true},
+
+		{StatusPending, StatusPaid, false},
+		{StatusShipped, StatusCancelled, false},
+		{StatusDelivered, StatusPending, false},
+		{StatusFailed, StatusReserved, false},
+		{StatusRefunded, StatusPaid, false},
+	}
+	for _, c := range cases {
+		if got := c.from.CanTransitionTo(c.to); got != c.want {
+			t.Errorf("%s -> %s: got %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestOrder_TransitionRecordsHistory(t *testing.T) {
+	at := time.Date(2025, 12, 28, 15, 0, 0, 0, time.UTC)
+	order := Order{ID: "o1", Status: StatusPending}
+
+	if err := order.Transition(StatusReserved, "stock reserved", at); err != nil {
+		t.Fatalf("Transition failed: %v", err)
+	}
+
+	if order.Status != StatusReserved {
+		t.Errorf("status is wrong: %v", order.Status)
+	}
+	want := StatusChange{From: StatusPending, To: StatusReserved, Reason: "stock reserved", At: at}
+	if len(order.History) != 1 || order.History[0] != want {
+		t.Errorf("history is wrong: %+v", order.History)
+	}
+}
+
+func TestOrder_TransitionRejectsIllegal(t *testing.T) {
+	order := Order{ID: "o1", Status: StatusShipped}
+
+	err := order.Transition(StatusCancelled, "too late", time.Now())
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if order.Status != StatusShipped || len(order.History) != 0 {
+		t.Errorf("order changed by rejected transition: %+v", order)
+	}
+}
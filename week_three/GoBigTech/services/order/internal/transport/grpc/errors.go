@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+var (
+	reserveErrors = map[codes.Code]error{
+		codes.InvalidArgument:    service.ErrInvalidOrder,
+		codes.FailedPrecondition: service.ErrInsufficientStock,
+	}
+	// A reservation that is missing or already released at commit time no
+	// longer holds the stock of the order, as after it expired.
+	commitErrors = map[codes.Code]error{
+		codes.NotFound:           service.ErrInsufficientStock,
+		codes.FailedPrecondition: service.ErrInsufficientStock,
+	}
+	// AlreadyExists, an order charged another amount before, is left out:
+	// the order was charged, so the failure must not count as a rejection.
+	paymentErrors = map[codes.Code]error{
+		codes.InvalidArgument:    service.ErrPaymentRejected,
+		codes.FailedPrecondition: service.ErrPaymentRejected,
+	}
+)
+
+// translateError turns a gRPC status error into a service error. callErrors
+// holds the codes whose meaning is specific to the call; codes that mean the
+// remote service could not be reached map to service.ErrUnavailable.
+func translateError(err error, callErrors map[codes.Code]error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	if target, ok := callErrors[st.Code()]; ok {
+		return fmt.Errorf("%w: %s", target, st.Message())
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", service.ErrUnavailable, st.Message())
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		name       string
+		callErrors map[codes.Code]error
+		code       codes.Code
+		want       error
+	}{
+		{"reserve invalid", reserveErrors, codes.InvalidArgument, service.ErrInvalidOrder},
+		{"reserve out of stock", reserveErrors, codes.FailedPrecondition, service.ErrInsufficientStock},
+		{"commit missing", commitErrors, codes.NotFound, service.ErrInsufficientStock},
+		{"commit released", commitErrors, codes.FailedPrecondition, service.ErrInsufficientStock},
+		{"payment invalid", paymentErrors, codes.InvalidArgument, service.ErrPaymentRejected},
+		{"payment declined", paymentErrors, codes.FailedPrecondition, service.ErrPaymentRejected},
+		{"unavailable", nil, codes.Unavailable, service.ErrUnavailable},
+		{"deadline", paymentErrors, codes.DeadlineExceeded, service.ErrUnavailable},
+		{"exhausted", reserveErrors, codes.ResourceExhausted, service.ErrUnavailable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := translateError(status.Error(c.code, "remote detail"), c.callErrors)
+			if !errors.Is(err, c.want) {
+				t.Fatalf("got %v, want %v", err, c.want)
+			}
+			if err.Error() != c.want.Error()+": remote detail" {
+				t.Errorf("message = %q, want the remote detail kept", err)
+			}
+		})
+	}
+}
+
+func TestTranslateError_PassesThroughUnmappedErrors(t *testing.T) {
+	if err := translateError(nil, reserveErrors); err != nil {
+		t.Errorf("nil error translated to %v", err)
+	}
+
+	internal := status.Error(codes.Internal, "mongo down")
+	if err := translateError(internal, reserveErrors); err != internal {
+		t.Errorf("unmapped code translated to %v", err)
+	}
+	notFound := status.Error(codes.NotFound, "reservation not found")
+	if err := translateError(notFound, paymentErrors); err != notFound {
+		t.Errorf("code mapped for another call translated to %v", err)
+	}
+	charged := status.Error(codes.AlreadyExists, "order was already charged another amount")
+	if err := translateError(charged, paymentErrors); errors.Is(err, service.ErrPaymentRejected) {
+		t.Errorf("charge of another amount translated to a rejection: %v", err)
+	}
+	plain := errors.New("not a status")
+	if err := translateError(plain, reserveErrors); err != plain {
+		t.Errorf("non-status error translated to %v", err)
+	}
+}
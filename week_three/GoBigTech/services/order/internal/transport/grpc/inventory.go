@@ -0,0 +1,57 @@
+// Package grpc adapts the gRPC services the order service depends on to the
+// clients of the service package.
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventorypb "github.com/bulbahal/GoBigTech/services/inventory/v1"
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+// InventoryClientAdapter implements service.InventoryClient on the inventory
+// gRPC service.
+type InventoryClientAdapter struct {
+	client inventorypb.InventoryServiceClient
+}
+
+func NewInventoryClient(client inventorypb.InventoryServiceClient) *InventoryClientAdapter {
+	return &InventoryClientAdapter{client: client}
+}
+
+func (i *InventoryClientAdapter) ReserveStock(ctx context.Context, orderID string, items []service.OrderItem) error {
+	pbItems := make([]*inventorypb.StockItem, len(items))
+	for idx, it := range items {
+		pbItems[idx] = &inventorypb.StockItem{
+			ProductId: it.ProductID,
+			Quantity:  int32(it.Quantity),
+		}
+	}
+	_, err := i.client.ReserveStock(ctx, &inventorypb.ReserveStockRequest{
+		OrderId: orderID,
+		Items:   pbItems,
+	})
+	return translateError(err, reserveErrors)
+}
+
+func (i *InventoryClientAdapter) CommitStock(ctx context.Context, orderID string) error {
+	_, err := i.client.CommitStock(ctx, &inventorypb.CommitStockRequest{
+		OrderId: orderID,
+	})
+	return translateError(err, commitErrors)
+}
+
+// ReleaseStock succeeds when inventory holds no reservation for orderID, as
+// after a reservation that failed: there is no stock to return.
+func (i *InventoryClientAdapter) ReleaseStock(ctx context.Context, orderID string) error {
+	_, err := i.client.ReleaseStock(ctx, &inventorypb.ReleaseStockRequest{
+		OrderId: orderID,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return translateError(err, nil)
+}
@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	inventorypb "github.com/bulbahal/GoBigTech/services/inventory/v1"
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+type stubInventory struct {
+	inventorypb.InventoryServiceClient
+	err error
+}
+
+func (s *stubInventory) CommitStock(context.Context, *inventorypb.CommitStockRequest, ...grpc.CallOption) (*inventorypb.CommitStockResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &inventorypb.CommitStockResponse{Success: true}, nil
+}
+
+func (s *stubInventory) ReleaseStock(context.Context, *inventorypb.ReleaseStockRequest, ...grpc.CallOption) (*inventorypb.ReleaseStockResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &inventorypb.ReleaseStockResponse{Success: true}, nil
+}
+
+func TestReleaseStock_MissingReservationIsReleased(t *testing.T) {
+	c := NewInventoryClient(&stubInventory{err: status.Error(codes.NotFound, "reservation not found")})
+	if err := c.ReleaseStock(context.Background(), "o1"); err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+}
+
+func TestReleaseStock_ReportsFailure(t *testing.T) {
+	c := NewInventoryClient(&stubInventory{err: status.Error(codes.Unavailable, "down")})
+	if err := c.ReleaseStock(context.Background(), "o1"); !errors.Is(err, service.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+func TestCommitStock_MissingReservation(t *testing.T) {
+	c := NewInventoryClient(&stubInventory{err: status.Error(codes.NotFound, "reservation not found")})
+	if err := c.CommitStock(context.Background(), "o1"); !errors.Is(err, service.ErrInsufficientStock) {
+		t.Fatalf("expected ErrInsufficientStock, got %v", err)
+	}
+}
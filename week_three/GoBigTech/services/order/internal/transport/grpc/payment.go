@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+	paymentpb "github.com/bulbahal/GoBigTech/services/payment/v1"
+)
+
+// PaymentClientAdapter implements service.PaymentClient on the payment gRPC
+// service.
+type PaymentClientAdapter struct {
+	client paymentpb.PaymentServiceClient
+}
+
+func NewPaymentClient(client paymentpb.PaymentServiceClient) *PaymentClientAdapter {
+	return &PaymentClientAdapter{client: client}
+}
+
+func (p *PaymentClientAdapter) ProcessPayment(ctx context.Context, orderID, userID string, amount service.Money, method string) (string, error) {
+	resp, err := p.client.ProcessPayment(ctx, &paymentpb.ProcessPaymentRequest{
+		OrderId: orderID,
+		UserId:  userID,
+		Amount: &paymentpb.Money{
+			Amount:   amount.Amount,
+			Currency: amount.Currency,
+		},
+		Method: method,
+	})
+	if err != nil {
+		return "", translateError(err, paymentErrors)
+	}
+	return resp.GetTransactionId(), nil
+}
+
+func (p *PaymentClientAdapter) RefundPayment(ctx context.Context, orderID, transactionID string) error {
+	_, err := p.client.RefundPayment(ctx, &paymentpb.RefundPaymentRequest{
+		TransactionId: transactionID,
+		OrderId:       orderID,
+	})
+	return translateError(err, nil)
+}
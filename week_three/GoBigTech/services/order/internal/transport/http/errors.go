@@ -0,0 +1,224 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+// Machine-readable error codes returned in the "code" field of problem
+// documents. The problem type is /problems/<code>.
+const (
+	CodeInvalidRequest     = "invalid_request"
+	CodeValidationFailed   = "validation_failed"
+	CodeInvalidOrder       = "invalid_order"
+	CodeInvalidFilter      = "invalid_filter"
+	CodeInvalidCursor      = "invalid_cursor"
+	CodeUnknownProduct     = "unknown_product"
+	CodeCurrencyMismatch   = "currency_mismatch"
+	CodePaymentRejected    = "payment_rejected"
+	CodeInsufficientStock  = "insufficient_stock"
+	CodeInvalidTransition  = "invalid_transition"
+	CodeStatusConflict     = "status_conflict"
+	CodeIdempotencyReused  = "idempotency_key_reused"
+	CodeIdempotencyPending = "idempotency_key_in_progress"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeUnavailable        = "service_unavailable"
+	CodeUpstreamFailure    = "upstream_failure"
+	CodeInternal           = "internal_error"
+)
+
+// errorMapping reports a service error as status and code with a fixed
+// detail. With domain set, the text the order service itself attached to
+// the error is shown instead, unless the error came back from a saga step,
+// whose text is made by the inventory and payment services.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+	detail string
+	domain bool
+}
+
+var errorMappings = []errorMapping{
+	{service.ErrOrderNotFound, http.StatusNotFound, CodeNotFound, "order not found", false},
+	{service.ErrInvalidOrder, http.StatusBadRequest, CodeInvalidOrder, "invalid order", true},
+	{service.ErrInvalidListFilter, http.StatusBadRequest, CodeInvalidFilter, "invalid list filter", true},
+	{service.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor, "invalid cursor", false},
+	{service.ErrUnknownProduct, http.StatusUnprocessableEntity, CodeUnknownProduct, "unknown product", true},
+	{service.ErrCurrencyMismatch, http.StatusUnprocessableEntity, CodeCurrencyMismatch, "items are priced in different currencies", true},
+	{service.ErrPaymentRejected, http.StatusUnprocessableEntity, CodePaymentRejected, "payment was rejected", false},
+	{service.ErrInsufficientStock, http.StatusConflict, CodeInsufficientStock, "not enough stock for the requested items", false},
+	{service.ErrInvalidTransition, http.StatusConflict, CodeInvalidTransition, "order status does not allow this change", true},
+	{service.ErrStatusConflict, http.StatusConflict, CodeStatusConflict, "order status changed concurrently, retry the request", false},
+	{service.ErrUnavailable, http.StatusServiceUnavailable, CodeUnavailable, "a dependency is temporarily unavailable, retry later", false},
+}
+
+// serviceProblem maps a service error to a problem. The full error is only
+// logged: clients get the detail of the mapping, or for unmapped errors the
+// fallback status, code and detail, so internal details do not leak.
+func serviceProblem(ctx context.Context, err error, fallbackStatus int, fallbackCode, fallbackDetail string) orderapi.Problem {
+	r := requestFromContext(ctx)
+	problem := newProblem(r, fallbackStatus, fallbackCode, fallbackDetail)
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			problem = newProblem(r, m.status, m.code, mappedDetail(err, m))
+			break
+		}
+	}
+	logProblem(r, problem, err)
+	return problem
+}
+
+// mappedDetail returns the text of the error that wraps m.err directly, such
+// as "invalid order: quantity must be greater than 0", when m allows domain
+// text; outer wrappers are left out.
+func mappedDetail(err error, m errorMapping) string {
+	var sagaErr *service.SagaError
+	if !m.domain || errors.As(err, &sagaErr) {
+		return m.detail
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if errors.Unwrap(e) == m.err {
+			return e.Error()
+		}
+	}
+	return m.detail
+}
+
+// writeProblem writes an RFC 7807 problem document and returns its trace ID.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) string {
+	problem := newProblem(r, status, code, detail)
+	sendProblem(w, problem)
+	return problem.TraceId
+}
+
+// newProblem builds a problem for r; r may be nil when the handler runs
+// outside an HTTP request, as in tests.
+func newProblem(r *http.Request, status int, code, detail string) orderapi.Problem {
+	problem := orderapi.Problem{
+		Type:    "/problems/" + code,
+		Title:   http.StatusText(status),
+		Status:  int32(status),
+		Code:    code,
+		TraceId: traceIDFromRequest(r),
+	}
+	if r != nil {
+		instance := r.URL.Path
+		problem.Instance = &instance
+	}
+	if detail != "" {
+		problem.Detail = &detail
+	}
+	return problem
+}
+
+// logProblem logs err with the problem it was reported as: server errors at
+// error level, client errors at info. The trace ID is added by the logger
+// when the request has a span.
+func logProblem(r *http.Request, problem orderapi.Problem, err error) {
+	level := slog.LevelError
+	if problem.Status < http.StatusInternalServerError {
+		level = slog.LevelInfo
+	}
+	attrs := []any{"status", problem.Status, "code", problem.Code, "error", err}
+	if r == nil {
+		slog.Log(context.Background(), level, "request failed", append(attrs, "trace_id", problem.TraceId)...)
+		return
+	}
+	if !trace.SpanContextFromContext(r.Context()).HasTraceID() {
+		attrs = append(attrs, "trace_id", problem.TraceId)
+	}
+	attrs = append(attrs, "method", r.Method, "path", r.URL.Path)
+	slog.Log(r.Context(), level, "request failed", attrs...)
+}
+
+func sendProblem(w http.ResponseWriter, problem orderapi.Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(int(problem.Status))
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// traceIDFromRequest takes the trace ID from the request's span, or from a
+// W3C traceparent header when tracing is not set up, so that a problem can be
+// matched with its trace, and generates a new one otherwise.
+func traceIDFromRequest(r *http.Request) string {
+	if r != nil {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			return sc.TraceID().String()
+		}
+
+		parts := strings.Split(r.Header.Get("traceparent"), "-")
+		if len(parts) == 4 && len(parts[1]) == 32 {
+			if _, err := hex.DecodeString(parts[1]); err == nil {
+				return parts[1]
+			}
+		}
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// HandleRequestError reports requests the generated router could not bind,
+// such as malformed query parameters or request bodies. The parser's message
+// is logged only, since it may echo parts of the request.
+func (h *Handler) HandleRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := newProblem(r, http.StatusBadRequest, CodeInvalidRequest, requestErrorDetail(err))
+	logProblem(r, problem, err)
+	sendProblem(w, problem)
+}
+
+// requestErrorDetail names the parameter a binding error is about; any other
+// error comes from decoding the body.
+func requestErrorDetail(err error) string {
+	var (
+		invalid   *orderapi.InvalidParamFormatError
+		unmarshal *orderapi.UnmarshalingParamError
+		required  *orderapi.RequiredParamError
+		header    *orderapi.RequiredHeaderError
+		tooMany   *orderapi.TooManyValuesForParamError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		return fmt.Sprintf("parameter %q is malformed", invalid.ParamName)
+	case errors.As(err, &unmarshal):
+		return fmt.Sprintf("parameter %q is malformed", unmarshal.ParamName)
+	case errors.As(err, &required):
+		return fmt.Sprintf("parameter %q is required", required.ParamName)
+	case errors.As(err, &header):
+		return fmt.Sprintf("header %q is required", header.ParamName)
+	case errors.As(err, &tooMany):
+		return fmt.Sprintf("parameter %q is given more than once", tooMany.ParamName)
+	}
+	return "request body is malformed"
+}
+
+// HandleResponseError reports errors returned by the strict handlers, which
+// return errors only for failures they have no documented response for.
+func (h *Handler) HandleResponseError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := newProblem(r, http.StatusInternalServerError, CodeInternal, "internal error")
+	logProblem(r, problem, err)
+	sendProblem(w, problem)
+}
+
+func (h *Handler) NotFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusNotFound, CodeNotFound, "no such resource")
+}
+
+func (h *Handler) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not supported here")
+}
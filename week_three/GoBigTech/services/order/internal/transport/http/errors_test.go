@@ -0,0 +1,216 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+func TestPostOrders_MapsServiceErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: quantity must be greater than 0", service.ErrInvalidOrder), http.StatusBadRequest, CodeInvalidOrder},
+		{fmt.Errorf("%w: \"p9\"", service.ErrUnknownProduct), http.StatusUnprocessableEntity, CodeUnknownProduct},
+		{&service.SagaError{Step: "reserve stock", Err: fmt.Errorf("%w: not enough stock", service.ErrInsufficientStock)}, http.StatusConflict, CodeInsufficientStock},
+		{&service.SagaError{Step: "process payment", Err: fmt.Errorf("%w: amount must be greater than 0", service.ErrPaymentRejected)}, http.StatusUnprocessableEntity, CodePaymentRejected},
+		{fmt.Errorf("%w: connection refused", service.ErrUnavailable), http.StatusServiceUnavailable, CodeUnavailable},
+		{errors.New("pgx: connection reset"), http.StatusBadGateway, CodeUpstreamFailure},
+	}
+	for _, c := range cases {
+		h := &Handler{Service: &mockOrderService{createErr: c.err}}
+		resp := postOrder(h, "", `{"user_id":"u1","items":[{"product_id":"p1","quantity":1}]}`)
+
+		if resp.Code != c.status {
+			t.Errorf("%v: expected status %d, got %d", c.err, c.status, resp.Code)
+		}
+		var body orderapi.Problem
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%v: decode body: %v", c.err, err)
+		}
+		if body.Code != c.code {
+			t.Errorf("%v: expected code %q, got %q", c.err, c.code, body.Code)
+		}
+	}
+}
+
+func TestPostOrders_UnmappedErrorHasGenericDetail(t *testing.T) {
+	h := &Handler{Service: &mockOrderService{createErr: errors.New("pgx: password authentication failed")}}
+	resp := postOrder(h, "", `{"user_id":"u1","items":[{"product_id":"p1","quantity":1}]}`)
+
+	var body orderapi.Problem
+	_ = json.Unmarshal(resp.Body.Bytes(), &body)
+	if body.Detail == nil || *body.Detail != "order processing error" {
+		t.Errorf("expected generic detail, got %v", body.Detail)
+	}
+}
+
+func TestHandleRequestError_HidesParserMessage(t *testing.T) {
+	cases := []struct {
+		err    error
+		detail string
+	}{
+		{fmt.Errorf("can't decode JSON body: %w", errors.New(`invalid character 's' looking for beginning of value "secret"`)), "request body is malformed"},
+		{&orderapi.InvalidParamFormatError{ParamName: "limit", Err: errors.New(`strconv.ParseInt: parsing "secret": invalid syntax`)}, `parameter "limit" is malformed`},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		(&Handler{}).HandleRequestError(rec, httptest.NewRequest(http.MethodGet, "/orders", nil), c.err)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected status 400, got %d", c.err, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "secret") {
+			t.Errorf("%v: response leaks the parser message: %s", c.err, rec.Body.String())
+		}
+		var body orderapi.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%v: decode body: %v", c.err, err)
+		}
+		if body.Code != CodeInvalidRequest || body.Detail == nil || *body.Detail != c.detail {
+			t.Errorf("%v: expected %s %q, got %s %v", c.err, CodeInvalidRequest, c.detail, body.Code, body.Detail)
+		}
+	}
+}
+
+func TestPostOrders_HidesInternalErrorText(t *testing.T) {
+	grpcMsg := "mongo release: server selection error, Addr: mongo-prod-1:27017"
+	cases := []struct {
+		err    error
+		detail string
+		leaks  []string
+	}{
+		{
+			err: &service.SagaError{
+				Step: "process payment",
+				Err:  fmt.Errorf("%w: card declined by acquirer 10.0.3.7", service.ErrPaymentRejected),
+				Outcomes: []service.StepOutcome{
+					{Step: "reserve stock", Status: service.StepCompensationFailed, Err: errors.New("rpc error: code = Internal desc = " + grpcMsg)},
+				},
+			},
+			detail: "payment was rejected",
+			leaks:  []string{grpcMsg, "10.0.3.7", "reserve stock", "process payment"},
+		},
+		{
+			err:    fmt.Errorf("%w: dial tcp inventory.internal:50051: connection refused", service.ErrUnavailable),
+			detail: "a dependency is temporarily unavailable, retry later",
+			leaks:  []string{"inventory.internal:50051"},
+		},
+		{
+			err:    &service.SagaError{Step: "reserve stock", Err: fmt.Errorf("%w: %s", service.ErrInvalidOrder, grpcMsg)},
+			detail: "invalid order",
+			leaks:  []string{grpcMsg, "reserve stock"},
+		},
+		{
+			err:    fmt.Errorf("price items: %w", fmt.Errorf("%w: quantity must be greater than 0", service.ErrInvalidOrder)),
+			detail: "invalid order: quantity must be greater than 0",
+			leaks:  []string{"price items"},
+		},
+	}
+	for _, c := range cases {
+		h := &Handler{Service: &mockOrderService{createErr: c.err}}
+		resp := postOrder(h, "", `{"user_id":"u1","items":[{"product_id":"p1","quantity":1}]}`)
+
+		var body orderapi.Problem
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%v: decode body: %v", c.err, err)
+		}
+		if body.Detail == nil || *body.Detail != c.detail {
+			t.Errorf("%v: expected detail %q, got %v", c.err, c.detail, body.Detail)
+		}
+		for _, leak := range c.leaks {
+			if strings.Contains(resp.Body.String(), leak) {
+				t.Errorf("response body leaks %q: %s", leak, resp.Body.String())
+			}
+		}
+	}
+}
+
+func TestWriteProblem_Document(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/orders/o1/cancel", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+
+	writeProblem(rec, req, http.StatusConflict, CodeInvalidTransition, "order in status shipped cannot be cancelled")
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	var body orderapi.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Type != "/problems/invalid_transition" || body.Title != "Conflict" || body.Status != http.StatusConflict {
+		t.Errorf("unexpected problem %+v", body)
+	}
+	if body.Instance == nil || *body.Instance != "/orders/o1/cancel" {
+		t.Errorf("unexpected instance %v", body.Instance)
+	}
+	if body.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected trace ID from traceparent, got %q", body.TraceId)
+	}
+}
+
+func TestWriteProblem_GeneratesTraceID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/orders/o1", nil)
+	rec := httptest.NewRecorder()
+
+	writeProblem(rec, req, http.StatusNotFound, CodeNotFound, "order not found")
+
+	var body orderapi.Problem
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if len(body.TraceId) != 32 {
+		t.Errorf("expected generated 32-char trace ID, got %q", body.TraceId)
+	}
+}
+
+func TestGetOrdersId_NotFoundVersusFailure(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{fmt.Errorf("%w: o1", service.ErrOrderNotFound), http.StatusNotFound},
+		{errors.New("load order o1: dial tcp: connection refused"), http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		h := &Handler{Service: &mockOrderService{getErr: c.err}}
+		rec := httptest.NewRecorder()
+		NewStrictHandler(h).GetOrdersId(rec, httptest.NewRequest(http.MethodGet, "/orders/o1", nil), "o1")
+
+		if rec.Code != c.status {
+			t.Errorf("%v: expected status %d, got %d", c.err, c.status, rec.Code)
+		}
+	}
+}
+
+func TestPostOrdersIdCancel_NotFound(t *testing.T) {
+	h := &Handler{Service: &mockOrderService{cancelErr: fmt.Errorf("%w: o1", service.ErrOrderNotFound)}}
+	rec := httptest.NewRecorder()
+	NewStrictHandler(h).PostOrdersIdCancel(rec, httptest.NewRequest(http.MethodPost, "/orders/o1/cancel", strings.NewReader(`{}`)), "o1")
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestGetOrdersId_UndocumentedStatusBecomesInternalError(t *testing.T) {
+	h := &Handler{Service: &mockOrderService{getErr: fmt.Errorf("%w: inventory down", service.ErrUnavailable)}}
+	rec := httptest.NewRecorder()
+	NewStrictHandler(h).GetOrdersId(rec, httptest.NewRequest(http.MethodGet, "/orders/o1", nil), "o1")
+
+	var body orderapi.Problem
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if rec.Code != http.StatusInternalServerError || body.Code != CodeInternal {
+		t.Errorf("expected 500 internal_error, got %d %q", rec.Code, body.Code)
+	}
+}
@@ -1,87 +1,227 @@
 package http
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"io"
 	"net/http"
 
 	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+	"github.com/bulbahal/GoBigTech/services/order/internal/idempotency"
 	"github.com/bulbahal/GoBigTech/services/order/internal/service"
 )
 
 type Handler struct {
 	Service service.OrderService
+	// Idempotency enables Idempotency-Key handling on POST /orders when set.
+	Idempotency idempotency.Store
+	// OrderIDs generates the order IDs recorded under idempotency keys;
+	// service.UUIDGenerator is used when nil.
+	OrderIDs service.IDGenerator
 }
 
-func (h *Handler) PostOrders(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+var _ orderapi.StrictServerInterface = (*Handler)(nil)
 
-	var body orderapi.CreateOrder
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
-		return
-	}
+// NewStrictHandler adapts h to the router generated from order.yaml. Request
+// bodies that cannot be decoded and errors returned by h are reported as
+// problems.
+func NewStrictHandler(h *Handler) orderapi.ServerInterface {
+	return orderapi.NewStrictHandlerWithOptions(h, []orderapi.StrictMiddlewareFunc{withHTTPRequest},
+		orderapi.StrictHTTPServerOptions{
+			RequestErrorHandlerFunc:  h.HandleRequestError,
+			ResponseErrorHandlerFunc: h.HandleResponseError,
+		})
+}
 
-	if body.UserId == "" || len(body.Items) == 0 {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
-		return
-	}
+type httpRequestKey struct{}
 
-	items := make([]service.OrderItem, len(body.Items))
-	for i, it := range body.Items {
-		items[i] = service.OrderItem{
-			ProductID: it.ProductId,
-			Quantity:  int(it.Quantity),
-		}
+// withHTTPRequest makes the request available to the strict handlers, which
+// only get a context, so that problems can name the instance and trace ID.
+func withHTTPRequest(f orderapi.StrictHandlerFunc, operationID string) orderapi.StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return f(context.WithValue(ctx, httpRequestKey{}, r), w, r, request)
 	}
+}
 
-	order, err := h.Service.CreateOrder(ctx, body.UserId, items)
-	if err != nil {
-		http.Error(w, "order processing error: "+err.Error(), http.StatusBadGateway)
-		return
-	}
+func requestFromContext(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(httpRequestKey{}).(*http.Request)
+	return r
+}
+
+// OptionalJSONBody turns an empty request body into an empty JSON object.
+// The strict handlers decode a body even where order.yaml makes it optional,
+// as for POST /orders/{id}/cancel; required bodies are enforced by Validator.
+func OptionalJSONBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.ContentLength == 0 {
+			r.Body = io.NopCloser(bytes.NewReader([]byte("{}")))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
+func toAPIOrder(order service.Order) orderapi.Order {
 	respItems := make([]orderapi.OrderItem, len(order.Items))
 	for i, it := range order.Items {
 		respItems[i] = orderapi.OrderItem{
 			ProductId: it.ProductID,
 			Quantity:  int32(it.Quantity),
+			UnitPrice: &orderapi.Money{
+				Amount:   it.UnitPrice.Amount,
+				Currency: it.UnitPrice.Currency,
+			},
 		}
 	}
 
-	resp := orderapi.Order{
+	return orderapi.Order{
 		Id:     order.ID,
 		UserId: order.UserID,
 		Status: orderapi.OrderStatus(order.Status),
 		Items:  respItems,
+		Total: orderapi.Money{
+			Amount:   order.Total.Amount,
+			Currency: order.Total.Currency,
+		},
+		CreatedAt: order.CreatedAt,
 	}
+}
+
+func (h *Handler) GetOrders(ctx context.Context, request orderapi.GetOrdersRequestObject) (orderapi.GetOrdersResponseObject, error) {
+	params := request.Params
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
+	var filter service.ListOrdersFilter
+	if params.UserId != nil {
+		filter.UserID = *params.UserId
+	}
+	if params.Status != nil {
+		filter.Status = service.OrderStatus(*params.Status)
+	}
+	if params.CreatedAfter != nil {
+		filter.CreatedAfter = *params.CreatedAfter
+	}
+	if params.Limit != nil {
+		filter.Limit = int(*params.Limit)
+	}
+	if params.Cursor != nil {
+		filter.Cursor = *params.Cursor
+	}
+
+	page, err := h.Service.ListOrders(ctx, filter)
+	if err != nil {
+		problem := serviceProblem(ctx, err, http.StatusInternalServerError, CodeInternal, "list orders error")
+		switch problem.Status {
+		case http.StatusBadRequest:
+			return orderapi.GetOrders400ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusInternalServerError:
+			return orderapi.GetOrders500ApplicationProblemPlusJSONResponse(problem), nil
+		}
+		return nil, err
+	}
+
+	resp := orderapi.OrderList{
+		Items: make([]orderapi.Order, len(page.Orders)),
+	}
+	for i, order := range page.Orders {
+		resp.Items[i] = toAPIOrder(order)
+	}
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+
+	return orderapi.GetOrders200JSONResponse(resp), nil
 }
 
-func (h *Handler) GetOrdersId(w http.ResponseWriter, r *http.Request, id string) {
-	ctx := r.Context()
-	order, err := h.Service.GetOrder(ctx, id)
+func (h *Handler) PostOrders(ctx context.Context, request orderapi.PostOrdersRequestObject) (orderapi.PostOrdersResponseObject, error) {
+	body := *request.Body
+	if body.UserId == "" || len(body.Items) == 0 {
+		problem := newProblem(requestFromContext(ctx), http.StatusBadRequest, CodeInvalidRequest, "invalid payload")
+		return orderapi.PostOrders400ApplicationProblemPlusJSONResponse(problem), nil
+	}
+
+	key := request.Params.IdempotencyKey
+	if key == nil || h.Idempotency == nil {
+		return h.createOrder(ctx, "", body)
+	}
+	return h.withIdempotency(ctx, http.MethodPost, "/orders", *key, body, func(orderID string) (orderapi.PostOrdersResponseObject, error) {
+		return h.createOrder(ctx, orderID, body)
+	})
+}
+
+// createOrder creates the order under orderID, or under a new ID when
+// orderID is empty.
+func (h *Handler) createOrder(ctx context.Context, orderID string, body orderapi.CreateOrder) (orderapi.PostOrdersResponseObject, error) {
+	items := make([]service.OrderItem, len(body.Items))
+	for i, it := range body.Items {
+		items[i] = service.OrderItem{
+			ProductID: it.ProductId,
+			Quantity:  int(it.Quantity),
+		}
+	}
+
+	var order service.Order
+	var err error
+	if orderID == "" {
+		order, err = h.Service.CreateOrder(ctx, body.UserId, items)
+	} else {
+		order, err = h.Service.CreateOrderWithID(ctx, orderID, body.UserId, items)
+	}
 	if err != nil {
-		http.Error(w, "not found", http.StatusNotFound)
-		return
+		problem := serviceProblem(ctx, err, http.StatusBadGateway, CodeUpstreamFailure, "order processing error")
+		switch problem.Status {
+		case http.StatusBadRequest:
+			return orderapi.PostOrders400ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusConflict:
+			return orderapi.PostOrders409ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusUnprocessableEntity:
+			return orderapi.PostOrders422ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusBadGateway:
+			return orderapi.PostOrders502ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusServiceUnavailable:
+			return orderapi.PostOrders503ApplicationProblemPlusJSONResponse(problem), nil
+		}
+		return nil, err
 	}
 
-	respItems := make([]orderapi.OrderItem, len(order.Items))
-	for i, it := range order.Items {
-		respItems[i] = orderapi.OrderItem{
-			ProductId: it.ProductID,
-			Quantity:  int32(it.Quantity),
+	return orderapi.PostOrders200JSONResponse(toAPIOrder(order)), nil
+}
+
+func (h *Handler) GetOrdersId(ctx context.Context, request orderapi.GetOrdersIdRequestObject) (orderapi.GetOrdersIdResponseObject, error) {
+	order, err := h.Service.GetOrder(ctx, request.Id)
+	if err != nil {
+		problem := serviceProblem(ctx, err, http.StatusInternalServerError, CodeInternal, "get order error")
+		switch problem.Status {
+		case http.StatusNotFound:
+			return orderapi.GetOrdersId404ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusInternalServerError:
+			return orderapi.GetOrdersId500ApplicationProblemPlusJSONResponse(problem), nil
 		}
+		return nil, err
 	}
 
-	resp := orderapi.Order{
-		Id:     order.ID,
-		UserId: order.UserID,
-		Status: orderapi.OrderStatus(order.Status),
-		Items:  respItems,
+	return orderapi.GetOrdersId200JSONResponse(toAPIOrder(order)), nil
+}
+
+func (h *Handler) PostOrdersIdCancel(ctx context.Context, request orderapi.PostOrdersIdCancelRequestObject) (orderapi.PostOrdersIdCancelResponseObject, error) {
+	var reason string
+	if request.Body != nil && request.Body.Reason != nil {
+		reason = *request.Body.Reason
+	}
+
+	order, err := h.Service.CancelOrder(ctx, request.Id, reason)
+	if err != nil {
+		problem := serviceProblem(ctx, err, http.StatusBadGateway, CodeUpstreamFailure, "order cancellation error")
+		switch problem.Status {
+		case http.StatusNotFound:
+			return orderapi.PostOrdersIdCancel404ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusConflict:
+			return orderapi.PostOrdersIdCancel409ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusBadGateway:
+			return orderapi.PostOrdersIdCancel502ApplicationProblemPlusJSONResponse(problem), nil
+		case http.StatusServiceUnavailable:
+			return orderapi.PostOrdersIdCancel503ApplicationProblemPlusJSONResponse(problem), nil
+		}
+		return nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
+	return orderapi.PostOrdersIdCancel200JSONResponse(toAPIOrder(order)), nil
 }
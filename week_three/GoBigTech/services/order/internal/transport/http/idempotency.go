@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+	"github.com/bulbahal/GoBigTech/services/order/internal/idempotency"
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+const (
+	// completeAttempts bounds how often storing a response is tried.
+	completeAttempts = 3
+	// completeBackoff is the pause before the first retry; it doubles after
+	// every failed attempt.
+	completeBackoff = 100 * time.Millisecond
+)
+
+// withIdempotency runs next at most once per Idempotency-Key and passes it
+// the order ID recorded under the key. Retries with the same request get the
+// stored response; a different request under the same key is rejected.
+// Server errors release the key so the client can retry. A key whose
+// response was never stored is claimed again after the store's lock timeout
+// with the order ID of the first attempt, so the retry completes that order
+// instead of creating a second one.
+func (h *Handler) withIdempotency(ctx context.Context, method, path, key string, body any, next func(orderID string) (orderapi.PostOrdersResponseObject, error)) (orderapi.PostOrdersResponseObject, error) {
+	r := requestFromContext(ctx)
+
+	canonical, err := json.Marshal(body)
+	if err != nil {
+		problem := newProblem(r, http.StatusBadRequest, CodeInvalidRequest, "invalid payload")
+		return orderapi.PostOrders400ApplicationProblemPlusJSONResponse(problem), nil
+	}
+	fingerprint := idempotency.Fingerprint(method, path, canonical)
+
+	ids := h.OrderIDs
+	if ids == nil {
+		ids = service.UUIDGenerator{}
+	}
+	orderID, err := ids.NewID()
+	if err != nil {
+		problem := newProblem(r, http.StatusInternalServerError, CodeInternal, "order id generation error")
+		logProblem(r, problem, err)
+		return orderapi.PostOrders500ApplicationProblemPlusJSONResponse(problem), nil
+	}
+
+	rec, claimed, err := h.Idempotency.Claim(ctx, key, fingerprint, orderID)
+	if err != nil {
+		problem := newProblem(r, http.StatusInternalServerError, CodeInternal, "idempotency store error")
+		logProblem(r, problem, err)
+		return orderapi.PostOrders500ApplicationProblemPlusJSONResponse(problem), nil
+	}
+	if !claimed {
+		switch {
+		case rec.Fingerprint != fingerprint:
+			problem := newProblem(r, http.StatusConflict, CodeIdempotencyReused, "Idempotency-Key was already used with a different request")
+			return orderapi.PostOrders409ApplicationProblemPlusJSONResponse(problem), nil
+		case !rec.Completed:
+			problem := newProblem(r, http.StatusConflict, CodeIdempotencyPending, "a request with this Idempotency-Key is still in progress")
+			return orderapi.PostOrders409ApplicationProblemPlusJSONResponse(problem), nil
+		}
+		return replayedResponse{rec: rec}, nil
+	}
+
+	if rec.OrderID != "" {
+		orderID = rec.OrderID
+	}
+	resp, err := next(orderID)
+	if err != nil {
+		h.releaseKey(context.WithoutCancel(ctx), key)
+		return nil, err
+	}
+	return &recordedResponse{
+		PostOrdersResponseObject: resp,
+		handler:                  h,
+		ctx:                      context.WithoutCancel(ctx),
+		key:                      key,
+		fingerprint:              fingerprint,
+	}, nil
+}
+
+func (h *Handler) releaseKey(ctx context.Context, key string) {
+	if err := h.Idempotency.Release(ctx, key); err != nil {
+		slog.WarnContext(ctx, "idempotency: release key failed", "key", key, "error", err)
+	}
+}
+
+// replayedResponse writes a stored response again, byte for byte.
+type replayedResponse struct {
+	rec idempotency.Record
+}
+
+func (resp replayedResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	if resp.rec.ContentType != "" {
+		w.Header().Set("Content-Type", resp.rec.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.rec.StatusCode)
+	_, err := w.Write(resp.rec.Body)
+	return err
+}
+
+// recordedResponse writes the wrapped response and stores a copy of it under
+// the idempotency key once it is written.
+type recordedResponse struct {
+	orderapi.PostOrdersResponseObject
+
+	handler     *Handler
+	ctx         context.Context
+	key         string
+	fingerprint string
+}
+
+func (resp *recordedResponse) VisitPostOrdersResponse(w http.ResponseWriter) error {
+	rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	visitErr := resp.PostOrdersResponseObject.VisitPostOrdersResponse(rw)
+
+	if visitErr != nil || rw.status >= http.StatusInternalServerError {
+		resp.handler.releaseKey(resp.ctx, resp.key)
+		return visitErr
+	}
+	resp.handler.completeKey(resp.ctx, resp.key, idempotency.Record{
+		Fingerprint: resp.fingerprint,
+		Completed:   true,
+		StatusCode:  rw.status,
+		ContentType: rw.Header().Get("Content-Type"),
+		Body:        rw.body.Bytes(),
+	})
+	return nil
+}
+
+// completeKey stores the response under key, retrying with backoff. If that
+// keeps failing the key stays claimed; a retry after the lock timeout then
+// repeats the same order, which the order service answers without creating
+// it twice.
+func (h *Handler) completeKey(ctx context.Context, key string, rec idempotency.Record) {
+	wait := completeBackoff
+	for attempt := 1; ; attempt++ {
+		err := h.Idempotency.Complete(ctx, key, rec)
+		if err == nil {
+			return
+		}
+		if attempt == completeAttempts {
+			slog.ErrorContext(ctx, "idempotency: complete key failed", "key", key, "attempts", attempt, "error", err)
+			return
+		}
+		slog.WarnContext(ctx, "idempotency: complete key failed, retrying", "key", key, "attempt", attempt, "error", err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// responseRecorder passes the response through while keeping a copy of the
+// status code and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+	"github.com/bulbahal/GoBigTech/services/order/internal/idempotency"
+	"github.com/bulbahal/GoBigTech/services/order/internal/service"
+)
+
+type mockOrderService struct {
+	service.OrderService
+
+	createErr      error
+	createCalls    int
+	createdOrderID string
+	getErr         error
+	cancelErr      error
+}
+
+func (m *mockOrderService) CreateOrderWithID(ctx context.Context, orderID, userID string, items []service.OrderItem) (service.Order, error) {
+	m.createdOrderID = orderID
+	order, err := m.CreateOrder(ctx, userID, items)
+	order.ID = orderID
+	return order, err
+}
+
+func (m *mockOrderService) CreateOrder(ctx context.Context, userID string, items []service.OrderItem) (service.Order, error) {
+	m.createCalls++
+	if m.createErr != nil {
+		return service.Order{}, m.createErr
+	}
+	for i := range items {
+		items[i].UnitPrice = service.Money{Amount: 100, Currency: "RUB"}
+	}
+	return service.Order{ID: "o1", UserID: userID, Status: service.StatusPaid, Items: items,
+		Total: service.Money{Amount: 100, Currency: "RUB"}}, nil
+}
+
+func (m *mockOrderService) GetOrder(ctx context.Context, id string) (service.Order, error) {
+	if m.getErr != nil {
+		return service.Order{}, m.getErr
+	}
+	return service.Order{ID: id, UserID: "u1", Status: service.StatusPaid,
+		Total: service.Money{Amount: 100, Currency: "RUB"}}, nil
+}
+
+func (m *mockOrderService) CancelOrder(ctx context.Context, id, reason string) (service.Order, error) {
+	if m.cancelErr != nil {
+		return service.Order{}, m.cancelErr
+	}
+	return service.Order{ID: id, UserID: "u1", Status: service.StatusCancelled,
+		Total: service.Money{Amount: 100, Currency: "RUB"}}, nil
+}
+
+type memoryStore struct {
+	records map[string]idempotency.Record
+	// expired makes unfinished keys claimable again, as after the lock
+	// timeout.
+	expired      bool
+	completeErrs int
+	completes    int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]idempotency.Record)}
+}
+
+func (m *memoryStore) Claim(ctx context.Context, key, fingerprint, orderID string) (idempotency.Record, bool, error) {
+	if rec, ok := m.records[key]; ok {
+		if m.expired && !rec.Completed && rec.Fingerprint == fingerprint {
+			return rec, true, nil
+		}
+		return rec, false, nil
+	}
+	m.records[key] = idempotency.Record{Fingerprint: fingerprint, OrderID: orderID}
+	return m.records[key], true, nil
+}
+
+func (m *memoryStore) Complete(ctx context.Context, key string, rec idempotency.Record) error {
+	m.completes++
+	if m.completes <= m.completeErrs {
+		return errors.New("postgres down")
+	}
+	m.records[key] = rec
+	return nil
+}
+
+func (m *memoryStore) Release(ctx context.Context, key string) error {
+	delete(m.records, key)
+	return nil
+}
+
+func postOrder(h *Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewStrictHandler(h).PostOrders(rec, req, orderapi.PostOrdersParams{IdempotencyKey: &key})
+	return rec
+}
+
+func TestPostOrders_IdempotentRetryIsReplayed(t *testing.T) {
+	svc := &mockOrderService{}
+	h := &Handler{Service: svc, Idempotency: newMemoryStore()}
+
+	body := `{"user_id":"u1","items":[{"product_id":"p1","quantity":2}]}`
+	first := postOrder(h, "k1", body)
+	// Same request with different formatting must still match.
+	second := postOrder(h, "k1", `{"items":[{"quantity":2,"product_id":"p1"}], "user_id":"u1"}`)
+
+	if svc.createCalls != 1 {
+		t.Fatalf("expected CreateOrder to be called once, got %d", svc.createCalls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replayed response differs: %d %q vs %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected replayed response to be marked")
+	}
+}
+
+func TestPostOrders_IdempotencyKeyReusedWithDifferentBody(t *testing.T) {
+	svc := &mockOrderService{}
+	h := &Handler{Service: svc, Idempotency: newMemoryStore()}
+
+	postOrder(h, "k1", `{"user_id":"u1","items":[{"product_id":"p1","quantity":2}]}`)
+	resp := postOrder(h, "k1", `{"user_id":"u1","items":[{"product_id":"p1","quantity":3}]}`)
+
+	if resp.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", resp.Code)
+	}
+	if svc.createCalls != 1 {
+		t.Errorf("expected CreateOrder to be called once, got %d", svc.createCalls)
+	}
+}
+
+func TestPostOrders_ServerErrorReleasesKey(t *testing.T) {
+	svc := &mockOrderService{createErr: errors.New("inventory down")}
+	store := newMemoryStore()
+	h := &Handler{Service: svc, Idempotency: store}
+
+	body := `{"user_id":"u1","items":[{"product_id":"p1","quantity":2}]}`
+	if resp := postOrder(h, "k1", body); resp.Code < http.StatusInternalServerError {
+		t.Fatalf("expected server error, got %d", resp.Code)
+	}
+	if _, ok := store.records["k1"]; ok {
+		t.Fatalf("expected key to be released after a server error")
+	}
+
+	svc.createErr = nil
+	if resp := postOrder(h, "k1", body); resp.Code != http.StatusOK {
+		t.Errorf("expected retry to succeed, got %d", resp.Code)
+	}
+	if svc.createCalls != 2 {
+		t.Errorf("expected CreateOrder to run again, got %d calls", svc.createCalls)
+	}
+}
+
+type staticIDs struct{ id string }
+
+func (g staticIDs) NewID() (string, error) { return g.id, nil }
+
+func TestPostOrders_CompleteIsRetried(t *testing.T) {
+	svc := &mockOrderService{}
+	store := newMemoryStore()
+	store.completeErrs = 2
+	h := &Handler{Service: svc, Idempotency: store}
+
+	body := `{"user_id":"u1","items":[{"product_id":"p1","quantity":2}]}`
+	if resp := postOrder(h, "k1", body); resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	if store.completes != 3 || !store.records["k1"].Completed {
+		t.Fatalf("expected the response to be stored on the third attempt, got %d attempts", store.completes)
+	}
+	if resp := postOrder(h, "k1", body); resp.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected the retry to be replayed")
+	}
+	if svc.createCalls != 1 {
+		t.Errorf("expected CreateOrder to be called once, got %d", svc.createCalls)
+	}
+}
+
+func TestPostOrders_ReclaimedKeyRepeatsSameOrder(t *testing.T) {
+	svc := &mockOrderService{}
+	store := newMemoryStore()
+	store.completeErrs = completeAttempts
+	h := &Handler{Service: svc, Idempotency: store, OrderIDs: staticIDs{id: "order-1"}}
+
+	body := `{"user_id":"u1","items":[{"product_id":"p1","quantity":2}]}`
+	postOrder(h, "k1", body)
+	if store.records["k1"].Completed {
+		t.Fatalf("expected the key to stay unfinished")
+	}
+
+	store.expired = true
+	h.OrderIDs = staticIDs{id: "order-2"}
+	if resp := postOrder(h, "k1", body); resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	if svc.createdOrderID != "order-1" {
+		t.Errorf("expected the retry to repeat order-1, got %q", svc.createdOrderID)
+	}
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	cases := []struct {
+		header string
+		keep   bool
+	}{
+		{"req-42", true},
+		{"", false},
+		{"has space", false},
+		{strings.Repeat("a", maxRequestIDLen+1), false},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		if c.header != "" {
+			req.Header.Set(RequestIDHeader, c.header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if seen == "" || rec.Header().Get(RequestIDHeader) != seen {
+			t.Errorf("header %q: context ID %q, response ID %q", c.header, seen, rec.Header().Get(RequestIDHeader))
+		}
+		if (seen == c.header) != c.keep {
+			t.Errorf("header %q: got ID %q, keep=%v", c.header, seen, c.keep)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(logging.New(&buf, "order", logging.Config{Level: "info", Format: logging.FormatJSON}))
+	defer slog.SetDefault(prev)
+
+	r := chi.NewRouter()
+	r.Use(RequestID, AccessLog)
+	r.Get("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/orders/o1", "/healthz"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("expected one access log line, got %q", buf.String())
+	}
+	var line map[string]any
+	if err := json.Unmarshal(lines[0], &line); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"level":              "ERROR",
+		"route":              "/orders/{id}",
+		"status":             float64(http.StatusBadGateway),
+		logging.KeyRequestID: "req-1",
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_LabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Metrics)
+	r.Get("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	for _, path := range []string{"/orders/a", "/orders/b", "/orders/missing", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	cases := []struct {
+		route, code string
+		want        float64
+	}{
+		{"/orders/{id}", "200", 2},
+		{"/orders/{id}", "404", 1},
+		{"unmatched", "404", 1},
+	}
+	for _, c := range cases {
+		if got := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, c.route, c.code)); got != c.want {
+			t.Errorf("requests{route=%q, code=%s} = %v, want %v", c.route, c.code, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+)
+
+// NewRouter serves the API of order.yaml with h. Requests are validated by v
+// only once chi has routed them, so tracing, the access log and the metrics
+// name rejected requests by their route pattern as well.
+func NewRouter(h *Handler, v *Validator) chi.Router {
+	r := chi.NewRouter()
+	r.Use(Tracing, RequestID, AccessLog, Metrics)
+	r.NotFound(h.NotFound)
+	r.MethodNotAllowed(h.MethodNotAllowed)
+	orderapi.HandlerWithOptions(NewStrictHandler(h), orderapi.ChiServerOptions{
+		BaseRouter: r,
+		// The last middleware runs first, so a missing body is replaced only
+		// after the validator has checked that it may be missing.
+		Middlewares:      []orderapi.MiddlewareFunc{OptionalJSONBody, v.Middleware},
+		ErrorHandlerFunc: h.HandleRequestError,
+	})
+	return r
+}
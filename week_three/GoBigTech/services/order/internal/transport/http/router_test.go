@@ -0,0 +1,67 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+)
+
+func TestNewRouter_InvalidRequestKeepsRoutePattern(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(prevProvider)
+
+	var buf bytes.Buffer
+	prevLogger := slog.Default()
+	slog.SetDefault(logging.New(&buf, "order", logging.Config{Level: "info", Format: logging.FormatJSON}))
+	defer slog.SetDefault(prevLogger)
+
+	counter := httpRequests.WithLabelValues(http.MethodPost, "/orders", "400")
+	before := testutil.ToFloat64(counter)
+
+	svc := &mockOrderService{}
+	router := newValidatedRouter(t, svc)
+	req := httptest.NewRequest(http.MethodPost, "/orders",
+		strings.NewReader(`{"user_id":"u1","items":[{"product_id":"p1","quantity":0}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body)
+	}
+	if svc.createCalls != 0 {
+		t.Errorf("expected handler not to be called")
+	}
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("requests{route=/orders, code=400} grew by %v, want 1", got)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got := spans[0].Name(); got != "POST /orders" {
+		t.Errorf("span name = %q, want POST /orders", got)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &line); err != nil {
+		t.Fatalf("access log: %v: %q", err, buf.String())
+	}
+	if line["route"] != "/orders" {
+		t.Errorf("access log route = %v, want /orders", line["route"])
+	}
+}
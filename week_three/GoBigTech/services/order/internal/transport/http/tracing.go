@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// probePaths are polled by the platform rather than called by clients, and
+// are neither traced nor logged.
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// Tracing starts a server span for every request, continuing the trace from
+// the traceparent header. The span is named after the chi route pattern
+// once routing is done, e.g. "POST /orders/{id}/cancel".
+func Tracing(next http.Handler) http.Handler {
+	routed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if route := routePattern(r); route != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+	})
+	// otelhttp renames the span after the handler only when r.Pattern is set
+	// on its own request, which the middlewares below replace; the chi route
+	// context is shared by every copy.
+	return otelhttp.NewHandler(routed, "order",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !probePaths[r.URL.Path]
+		}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if route := routePattern(r); route != "" {
+				return r.Method + " " + route
+			}
+			return r.Method
+		}),
+	)
+}
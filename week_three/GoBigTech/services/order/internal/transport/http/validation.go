@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+)
+
+// Validator checks requests against the OpenAPI document before they reach
+// the handlers. With response validation enabled it also checks what the
+// handlers send back and logs every mismatch; that costs a copy of each
+// response body and is meant for development.
+type Validator struct {
+	router            routers.Router
+	validateResponses bool
+}
+
+func NewValidator(doc *openapi3.T, validateResponses bool) (*Validator, error) {
+	// Requests are matched by path only, whatever host the service runs on.
+	doc.Servers = nil
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router, validateResponses: validateResponses}, nil
+}
+
+// NewSpecValidator builds a Validator for the embedded order.yaml.
+func NewSpecValidator(validateResponses bool) (*Validator, error) {
+	doc, err := orderapi.GetSwagger()
+	if err != nil {
+		return nil, err
+	}
+	return NewValidator(doc, validateResponses)
+}
+
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			// Unknown paths and methods are answered by the router.
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			problem := newProblem(r, http.StatusBadRequest, CodeValidationFailed, "request does not match the API specification")
+			fieldErrs := fieldErrors(err)
+			problem.Errors = &fieldErrs
+			sendProblem(w, problem)
+			return
+		}
+
+		if !v.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rw.status,
+			Header:                 rw.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(rw.body.Bytes())),
+			Options: &openapi3filter.Options{
+				MultiError:            true,
+				IncludeResponseStatus: true,
+			},
+		})
+		if err != nil {
+			slog.WarnContext(r.Context(), "response does not match the API specification",
+				"method", r.Method, "path", r.URL.Path, "status", rw.status, "error", err)
+		}
+	})
+}
+
+// fieldErrors flattens a validation error into one entry per invalid value.
+// Body fields are named by their JSON path (items.0.quantity), parameters by
+// location and name (query.limit).
+func fieldErrors(err error) []orderapi.FieldError {
+	// Matched by type rather than errors.As: MultiError.As stops at the first
+	// match, and RequestError unwraps to the schema errors it describes.
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var out []orderapi.FieldError
+		for _, inner := range e {
+			out = append(out, fieldErrors(inner)...)
+		}
+		return out
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			return schemaFieldErrors(e.Err, e.Parameter.In+"."+e.Parameter.Name, e.Reason)
+		case e.RequestBody != nil:
+			return schemaFieldErrors(e.Err, "body", e.Reason)
+		}
+	}
+	return []orderapi.FieldError{{Field: "request", Reason: err.Error()}}
+}
+
+func schemaFieldErrors(err error, field, reason string) []orderapi.FieldError {
+	if err == nil {
+		return []orderapi.FieldError{{Field: field, Reason: reason}}
+	}
+
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var out []orderapi.FieldError
+		for _, e := range multi {
+			out = append(out, schemaFieldErrors(e, field, reason)...)
+		}
+		return out
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if field == "body" {
+			if path := schemaErr.JSONPointer(); len(path) > 0 {
+				field = strings.Join(path, ".")
+			}
+		}
+		return []orderapi.FieldError{{Field: field, Reason: schemaErr.Reason}}
+	}
+
+	return []orderapi.FieldError{{Field: field, Reason: err.Error()}}
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	orderapi "github.com/bulbahal/GoBigTech/services/order/api"
+)
+
+func newValidatedRouter(t *testing.T, svc *mockOrderService) http.Handler {
+	t.Helper()
+
+	v, err := NewSpecValidator(true)
+	if err != nil {
+		t.Fatalf("validator: %v", err)
+	}
+	return NewRouter(&Handler{Service: svc}, v)
+}
+
+func TestValidator_RejectsInvalidBody(t *testing.T) {
+	svc := &mockOrderService{}
+	router := newValidatedRouter(t, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders",
+		strings.NewReader(`{"user_id":"u1","items":[{"product_id":"p1","quantity":0}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body)
+	}
+	if svc.createCalls != 0 {
+		t.Errorf("expected handler not to be called")
+	}
+
+	var body orderapi.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != CodeValidationFailed || body.Errors == nil {
+		t.Fatalf("unexpected problem %+v", body)
+	}
+	errs := *body.Errors
+	if len(errs) != 1 || errs[0].Field != "items.0.quantity" {
+		t.Errorf("expected a single error on items.0.quantity, got %+v", errs)
+	}
+}
+
+func TestValidator_ReportsEveryViolation(t *testing.T) {
+	router := newValidatedRouter(t, &mockOrderService{})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"items":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var body orderapi.Problem
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Errors == nil || len(*body.Errors) != 2 {
+		t.Fatalf("expected errors for user_id and items, got %s", rec.Body)
+	}
+}
+
+func TestValidator_RejectsInvalidQuery(t *testing.T) {
+	router := newValidatedRouter(t, &mockOrderService{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders?limit=500", nil))
+
+	var body orderapi.Problem
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if rec.Code != http.StatusBadRequest || body.Errors == nil || (*body.Errors)[0].Field != "query.limit" {
+		t.Errorf("expected 400 on query.limit, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestValidator_CombinesParameterAndBodyErrors(t *testing.T) {
+	router := newValidatedRouter(t, &mockOrderService{})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders",
+		strings.NewReader(`{"user_id":"u1","items":[{"product_id":"p1","quantity":-1}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strings.Repeat("k", 300))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var body orderapi.Problem
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Errors == nil || len(*body.Errors) != 2 {
+		t.Fatalf("expected header and body errors, got %s", rec.Body)
+	}
+	if (*body.Errors)[0].Field != "header.Idempotency-Key" || (*body.Errors)[1].Field != "items.0.quantity" {
+		t.Errorf("unexpected fields %+v", *body.Errors)
+	}
+}
+
+func TestValidator_AllowsCancelWithoutBody(t *testing.T) {
+	router := newValidatedRouter(t, &mockOrderService{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders/o1/cancel", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for a cancel without body, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestValidator_PassesValidRequest(t *testing.T) {
+	svc := &mockOrderService{}
+	router := newValidatedRouter(t, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders",
+		strings.NewReader(`{"user_id":"u1","items":[{"product_id":"p1","quantity":2}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || svc.createCalls != 1 {
+		t.Errorf("expected request to reach the handler, got %d: %s", rec.Code, rec.Body)
+	}
+}
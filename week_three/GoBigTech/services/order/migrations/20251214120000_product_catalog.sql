@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE products (
+                          id TEXT PRIMARY KEY,
+                          name TEXT NOT NULL,
+                          unit_price NUMERIC(12, 2) NOT NULL CHECK (unit_price >= 0),
+                          currency CHAR(3) NOT NULL
+);
+
+INSERT INTO products (id, name, unit_price, currency) VALUES
+    ('p1', 'Keyboard', 49.90, 'RUB'),
+    ('p2', 'Mouse', 19.99, 'RUB');
+
+ALTER TABLE orders
+    ADD COLUMN total NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE order_items
+    ADD COLUMN unit_price NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE order_items DROP COLUMN unit_price;
+ALTER TABLE orders DROP COLUMN currency, DROP COLUMN total;
+DROP TABLE products;
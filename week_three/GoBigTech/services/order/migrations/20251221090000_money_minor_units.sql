@@ -0,0 +1,42 @@
+-- +goose Up
+ALTER TABLE products ADD COLUMN unit_price_amount BIGINT;
+UPDATE products SET unit_price_amount = ROUND(unit_price * 100);
+ALTER TABLE products
+    ALTER COLUMN unit_price_amount SET NOT NULL,
+    ADD CONSTRAINT products_unit_price_amount_check CHECK (unit_price_amount >= 0),
+    DROP COLUMN unit_price;
+
+ALTER TABLE orders ADD COLUMN total_amount BIGINT NOT NULL DEFAULT 0;
+UPDATE orders SET total_amount = ROUND(total * 100);
+ALTER TABLE orders
+    ALTER COLUMN currency DROP DEFAULT,
+    DROP COLUMN total;
+
+ALTER TABLE order_items
+    ADD COLUMN unit_price_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3);
+UPDATE order_items oi
+SET unit_price_amount = ROUND(oi.unit_price * 100),
+    currency = o.currency
+FROM orders o
+WHERE o.id = oi.order_id;
+ALTER TABLE order_items
+    ALTER COLUMN currency SET NOT NULL,
+    DROP COLUMN unit_price;
+
+-- +goose Down
+ALTER TABLE order_items ADD COLUMN unit_price NUMERIC(12, 2) NOT NULL DEFAULT 0;
+UPDATE order_items SET unit_price = unit_price_amount / 100.0;
+ALTER TABLE order_items DROP COLUMN currency, DROP COLUMN unit_price_amount;
+
+ALTER TABLE orders ADD COLUMN total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+UPDATE orders SET total = total_amount / 100.0;
+ALTER TABLE orders
+    ALTER COLUMN currency SET DEFAULT 'RUB',
+    DROP COLUMN total_amount;
+
+ALTER TABLE products ADD COLUMN unit_price NUMERIC(12, 2);
+UPDATE products SET unit_price = unit_price_amount / 100.0;
+ALTER TABLE products
+    ALTER COLUMN unit_price SET NOT NULL,
+    DROP COLUMN unit_price_amount;
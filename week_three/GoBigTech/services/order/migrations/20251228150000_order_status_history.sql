@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE order_status_history (
+                                      id BIGSERIAL PRIMARY KEY,
+                                      order_id TEXT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                                      from_status TEXT,
+                                      to_status TEXT NOT NULL,
+                                      reason TEXT NOT NULL DEFAULT '',
+                                      created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX order_status_history_order_id_idx ON order_status_history (order_id, created_at);
+
+-- +goose Down
+DROP TABLE order_status_history;
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE orders ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX orders_created_at_id_idx ON orders (created_at DESC, id DESC);
+CREATE INDEX orders_user_id_created_at_id_idx ON orders (user_id, created_at DESC, id DESC);
+CREATE INDEX order_items_order_id_idx ON order_items (order_id);
+
+-- +goose Down
+DROP INDEX order_items_order_id_idx;
+DROP INDEX orders_user_id_created_at_id_idx;
+DROP INDEX orders_created_at_id_idx;
+ALTER TABLE orders DROP COLUMN created_at;
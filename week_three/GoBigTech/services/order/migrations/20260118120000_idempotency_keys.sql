@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE idempotency_keys (
+                                  key TEXT PRIMARY KEY,
+                                  fingerprint TEXT NOT NULL,
+                                  status_code INT,
+                                  content_type TEXT,
+                                  response_body BYTEA,
+                                  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                  completed_at TIMESTAMPTZ
+);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE outbox (
+                        id BIGSERIAL PRIMARY KEY,
+                        aggregate_id TEXT NOT NULL,
+                        event_type TEXT NOT NULL,
+                        payload JSONB NOT NULL,
+                        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                        attempts INT NOT NULL DEFAULT 0,
+                        next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                        last_error TEXT,
+                        published_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at, id) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP TABLE outbox;
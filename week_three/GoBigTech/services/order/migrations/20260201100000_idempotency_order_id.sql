@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE idempotency_keys ADD COLUMN order_id TEXT;
+
+-- +goose Down
+ALTER TABLE idempotency_keys DROP COLUMN order_id;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE order_quotes (
+    order_id TEXT PRIMARY KEY,
+    items JSONB NOT NULL,
+    total_amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE order_quotes;
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/config"
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+	"github.com/bulbahal/GoBigTech/services/shared/tracing"
+)
+
+type Config struct {
+	GRPCAddr        string         `yaml:"grpc_addr" env:"PAYMENT_GRPC_ADDR" flag:"grpc-addr" default:"127.0.0.1:50052" usage:"gRPC listen address"`
+	MetricsAddr     string         `yaml:"metrics_addr" env:"PAYMENT_METRICS_ADDR" flag:"metrics-addr" default:"127.0.0.1:9092" usage:"Prometheus metrics listen address"`
+	Log             logging.Config `yaml:"log"`
+	Tracing         tracing.Config `yaml:"tracing"`
+	HealthInterval  time.Duration  `yaml:"health_interval" env:"PAYMENT_HEALTH_INTERVAL" flag:"health-interval" default:"5s" usage:"how often readiness checks run"`
+	ShutdownTimeout time.Duration  `yaml:"shutdown_timeout" env:"PAYMENT_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" default:"10s" usage:"how long in-flight RPCs may finish on shutdown"`
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+	errs = append(errs,
+		config.CheckAddr("grpc_addr", c.GRPCAddr),
+		config.CheckAddr("metrics_addr", c.MetricsAddr),
+		c.Log.Validate(),
+		c.Tracing.Validate(),
+	)
+	if c.HealthInterval <= 0 {
+		errs = append(errs, errors.New("health_interval must be positive"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown_timeout must be positive"))
+	}
+	return errors.Join(errs...)
+}
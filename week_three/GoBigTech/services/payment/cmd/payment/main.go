@@ -2,28 +2,201 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	paymentpb "github.com/bulbahal/GoBigTech/services/payment/v1"
+	"github.com/bulbahal/GoBigTech/services/shared/config"
+	"github.com/bulbahal/GoBigTech/services/shared/grpcserver"
+	"github.com/bulbahal/GoBigTech/services/shared/health"
+	"github.com/bulbahal/GoBigTech/services/shared/logging"
+	"github.com/bulbahal/GoBigTech/services/shared/metrics"
+	"github.com/bulbahal/GoBigTech/services/shared/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+type transaction struct {
+	orderID  string
+	amount   int64
+	currency string
+	refunded bool
+}
+
 type server struct {
 	paymentpb.UnimplementedPaymentServiceServer
+
+	mu           sync.Mutex
+	seq          int
+	transactions map[string]*transaction
+	// byOrder maps an order to its transaction. An order refunded before it
+	// was charged maps to "", so a late charge cannot go through.
+	byOrder map[string]string
+}
+
+func newServer() *server {
+	return &server{
+		transactions: make(map[string]*transaction),
+		byOrder:      make(map[string]string),
+	}
 }
 
+// ProcessPayment charges an order once; a repeated request returns the
+// transaction of the first one, so the caller may retry it. A repeat with
+// another amount fails with AlreadyExists, since the order stays charged.
 func (s *server) ProcessPayment(ctx context.Context, req *paymentpb.ProcessPaymentRequest) (*paymentpb.ProcessPaymentResponse, error) {
-	return &paymentpb.ProcessPaymentResponse{Success: true, TransactionId: "tx_123"}, nil
+	amount := req.GetAmount()
+	if req.GetOrderId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+	if amount.GetAmount() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be greater than 0")
+	}
+	if len(amount.GetCurrency()) != 3 {
+		return nil, status.Error(codes.InvalidArgument, "currency must be an ISO 4217 code")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if txID, ok := s.byOrder[req.GetOrderId()]; ok {
+		tx := s.transactions[txID]
+		switch {
+		case tx == nil || tx.refunded:
+			return nil, status.Error(codes.FailedPrecondition, "payment of the order was refunded")
+		case tx.amount != amount.GetAmount() || tx.currency != amount.GetCurrency():
+			return nil, status.Error(codes.AlreadyExists, "order was already charged another amount")
+		}
+		return &paymentpb.ProcessPaymentResponse{Success: true, TransactionId: txID}, nil
+	}
+
+	s.seq++
+	txID := fmt.Sprintf("tx_%d", s.seq)
+	s.transactions[txID] = &transaction{
+		orderID:  req.GetOrderId(),
+		amount:   amount.GetAmount(),
+		currency: amount.GetCurrency(),
+	}
+	s.byOrder[req.GetOrderId()] = txID
+	paymentsProcessed.WithLabelValues(amount.GetCurrency()).Inc()
+	paymentAmountProcessed.WithLabelValues(amount.GetCurrency()).Add(float64(amount.GetAmount()))
+
+	return &paymentpb.ProcessPaymentResponse{Success: true, TransactionId: txID}, nil
+}
+
+// RefundPayment refunds a transaction, or without a transaction ID the
+// payment of the order, if any. Refunding twice succeeds.
+func (s *server) RefundPayment(ctx context.Context, req *paymentpb.RefundPaymentRequest) (*paymentpb.RefundPaymentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txID := req.GetTransactionId()
+	if txID == "" {
+		if req.GetOrderId() == "" {
+			return nil, status.Error(codes.InvalidArgument, "transaction_id or order_id is required")
+		}
+		var ok bool
+		if txID, ok = s.byOrder[req.GetOrderId()]; !ok || txID == "" {
+			s.byOrder[req.GetOrderId()] = ""
+			return &paymentpb.RefundPaymentResponse{Success: true}, nil
+		}
+	}
+
+	tx, ok := s.transactions[txID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "transaction %q not found", txID)
+	}
+	if req.GetOrderId() != "" && req.GetOrderId() != tx.orderID {
+		return nil, status.Error(codes.InvalidArgument, "transaction belongs to another order")
+	}
+	if !tx.refunded {
+		paymentAmountRefunded.WithLabelValues(tx.currency).Add(float64(tx.amount))
+	}
+	tx.refunded = true
+
+	return &paymentpb.RefundPaymentResponse{Success: true}, nil
 }
 
+// main exits non-zero only after run has returned, so the deferred cleanup
+// still runs when the gRPC server fails.
 func main() {
-	l, err := net.Listen("tcp4", "127.0.0.1:50052")
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var cfg Config
+	config.MustLoad("payment", &cfg)
+	logging.Setup("payment", cfg.Log)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "payment", cfg.Tracing)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("tracing setup failed", "error", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("tracing shutdown failed", "error", err)
+		}
+	}()
+
+	l, err := net.Listen("tcp4", cfg.GRPCAddr)
+	if err != nil {
+		logging.Fatal("listen failed", "addr", cfg.GRPCAddr, "error", err)
+	}
+	g := grpc.NewServer(
+		grpc.StatsHandler(tracing.ServerHandler()),
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()),
+	)
+	paymentpb.RegisterPaymentServiceServer(g, newServer())
+
+	// Payment keeps its state in memory, so readiness only reflects shutdown.
+	healthServer := health.NewGRPCServer(health.NewProbe(time.Second), paymentpb.PaymentService_ServiceDesc.ServiceName)
+	healthServer.Register(g)
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	defer stopHealth()
+	go healthServer.Watch(healthCtx, cfg.HealthInterval)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("payment listening", "addr", cfg.GRPCAddr)
+		serveErr <- g.Serve(l)
+	}()
+
+	metricsSrv := metrics.NewServer(cfg.MetricsAddr)
+	go func() {
+		slog.Info("payment metrics listening", "addr", cfg.MetricsAddr, "path", metrics.Path)
+		if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed", "error", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var failed error
+	select {
+	case <-ctx.Done():
+		slog.Info("payment shutting down")
+	case failed = <-serveErr:
+		slog.Error("grpc server failed", "error", failed)
+	}
+
+	healthServer.Shutdown()
+	if !grpcserver.GracefulStop(g, cfg.ShutdownTimeout) {
+		slog.Warn("in-flight RPCs cancelled", "after", cfg.ShutdownTimeout)
 	}
-	g := grpc.NewServer()
-	paymentpb.RegisterPaymentServiceServer(g, &server{})
-	log.Println("payment listening on 127.0.0.1:50052")
-	log.Fatal(g.Serve(l))
+	_ = metricsSrv.Close()
+	slog.Info("payment stopped")
+	return failed
 }
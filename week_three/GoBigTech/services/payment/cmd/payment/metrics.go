@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Amounts are counted in minor units of their currency, as they arrive.
+var (
+	paymentsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_processed_total",
+		Help: "Payments processed, by currency.",
+	}, []string{"currency"})
+	paymentAmountProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_processed_amount_minor_units_total",
+		Help: "Sum of processed payment amounts in minor units, by currency.",
+	}, []string{"currency"})
+	paymentAmountRefunded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_refunded_amount_minor_units_total",
+		Help: "Sum of refunded payment amounts in minor units, by currency.",
+	}, []string{"currency"})
+)
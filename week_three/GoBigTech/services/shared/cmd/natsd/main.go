@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus/embedded"
+)
+
+func main() {
+	host := flag.String("host", "127.0.0.1", "listen host")
+	port := flag.Int("port", 4222, "listen port")
+	storeDir := flag.String("store", filepath.Join(os.TempDir(), "natsd"), "JetStream storage directory")
+	flag.Parse()
+
+	srv, err := embedded.Start(*host, *port, *storeDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("nats listening on %s", srv.ClientURL())
+	srv.WaitForShutdown()
+}
@@ -0,0 +1,314 @@
+// Package config loads service configuration from defaults, a YAML file,
+// environment variables and command-line flags.
+//
+// A configuration is a struct whose fields carry the tags
+//
+//	yaml:"http_addr"          key in the YAML file
+//	env:"ORDER_HTTP_ADDR"     environment variable
+//	flag:"http-addr"          command-line flag
+//	default:":8080"           value used when no source sets the field
+//	usage:"..."               flag help text
+//	secret:"true"             redacted by Print
+//	validate:"required"       must not be empty after loading
+//
+// Nested structs group fields into YAML sections. Supported field types are
+// string, bool, the integer types and time.Duration.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the YAML file when --config is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Validator is implemented by configurations with checks beyond required
+// fields; Validate runs after every source has been applied.
+type Validator interface {
+	Validate() error
+}
+
+// Options describes where Load reads from; zero values mean the process
+// arguments and environment.
+type Options struct {
+	Name      string
+	Args      []string
+	LookupEnv func(string) (string, bool)
+	Output    io.Writer
+}
+
+// MustLoad loads cfg for the named service from the process arguments and
+// environment. It exits after printing the redacted configuration when
+// --print-config is given, and on any configuration error.
+func MustLoad(name string, cfg any) {
+	printOnly, err := Load(cfg, Options{Name: name})
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("%s config: %v", name, err)
+	}
+	if printOnly {
+		if err := Print(os.Stdout, cfg); err != nil {
+			log.Fatalf("%s config: %v", name, err)
+		}
+		os.Exit(0)
+	}
+}
+
+// Load fills cfg, a pointer to a configuration struct. Sources are applied in
+// increasing precedence: defaults, the YAML file (--config or CONFIG_FILE),
+// environment variables, flags. printOnly reports --print-config.
+func Load(cfg any, opts Options) (printOnly bool, err error) {
+	if opts.Args == nil {
+		opts.Args = os.Args[1:]
+	}
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stderr
+	}
+
+	root := reflect.ValueOf(cfg)
+	if root.Kind() != reflect.Pointer || root.Elem().Kind() != reflect.Struct {
+		return false, fmt.Errorf("config: %T is not a pointer to a struct", cfg)
+	}
+	fields, err := collectFields(root.Elem(), "")
+	if err != nil {
+		return false, err
+	}
+
+	fs := flag.NewFlagSet(opts.Name, flag.ContinueOnError)
+	fs.SetOutput(opts.Output)
+	configFile := fs.String("config", "", "path to a YAML config file (env "+ConfigFileEnv+")")
+	fs.BoolVar(&printOnly, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		if f.flag == "" {
+			continue
+		}
+		name := f.flag
+		usage := f.usage
+		if f.env != "" {
+			usage += " (env " + f.env + ")"
+		}
+		if f.def != "" {
+			usage += fmt.Sprintf(" (default %q)", f.def)
+		}
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(name, usage, func(s string) error { flagValues[name] = s; return nil })
+			continue
+		}
+		fs.Func(name, usage, func(s string) error { flagValues[name] = s; return nil })
+	}
+	if err := fs.Parse(opts.Args); err != nil {
+		return false, err
+	}
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		if err := f.set(f.def); err != nil {
+			return false, fmt.Errorf("default for %s: %w", f.path, err)
+		}
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = opts.LookupEnv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return false, err
+		}
+	}
+
+	for _, f := range fields {
+		if f.env == "" {
+			continue
+		}
+		if v, ok := opts.LookupEnv(f.env); ok {
+			if err := f.set(v); err != nil {
+				return false, fmt.Errorf("%s: %w", f.env, err)
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := flagValues[f.flag]; ok {
+			if err := f.set(v); err != nil {
+				return false, fmt.Errorf("--%s: %w", f.flag, err)
+			}
+		}
+	}
+
+	var errs []error
+	for _, f := range fields {
+		if f.required && f.value.IsZero() {
+			errs = append(errs, fmt.Errorf("%s is required", f.describe()))
+		}
+	}
+	if v, ok := cfg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return printOnly, errors.Join(errs...)
+}
+
+func loadFile(path string, cfg any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Print writes cfg as YAML with every secret field redacted.
+func Print(w io.Writer, cfg any) error {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	redacted := reflect.New(v.Type()).Elem()
+	redacted.Set(v)
+
+	fields, err := collectFields(redacted, "")
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.secret && f.value.Kind() == reflect.String && f.value.String() != "" {
+			f.value.SetString(Redact(f.value.String()))
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(redacted.Interface()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+type field struct {
+	path     string
+	value    reflect.Value
+	env      string
+	flag     string
+	def      string
+	usage    string
+	secret   bool
+	required bool
+}
+
+func (f field) describe() string {
+	names := []string{f.path}
+	if f.env != "" {
+		names = append(names, f.env)
+	}
+	if f.flag != "" {
+		names = append(names, "--"+f.flag)
+	}
+	return strings.Join(names, " / ")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func collectFields(v reflect.Value, prefix string) ([]field, error) {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		path := prefix + name
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && sf.Type != durationType {
+			nested, err := collectFields(fv, path+".")
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64:
+		default:
+			return nil, fmt.Errorf("config: field %s has unsupported type %s", path, sf.Type)
+		}
+		fields = append(fields, field{
+			path:     path,
+			value:    fv,
+			env:      sf.Tag.Get("env"),
+			flag:     sf.Tag.Get("flag"),
+			def:      sf.Tag.Get("default"),
+			usage:    sf.Tag.Get("usage"),
+			secret:   sf.Tag.Get("secret") == "true",
+			required: sf.Tag.Get("validate") == "required",
+		})
+	}
+	return fields, nil
+}
+
+func (f field) set(s string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(s)
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	default:
+		n, err := strconv.ParseInt(s, 10, f.value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(n)
+	}
+	return nil
+}
+
+// CheckAddr reports whether addr is a host:port listen or dial address.
+func CheckAddr(name, addr string) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// redacted matches what url.URL.Redacted puts in place of a password.
+const redacted = "xxxxx"
+
+var passwordParam = regexp.MustCompile(`(?i)(password=)\S+`)
+
+// Redact hides the credentials in a secret value. URLs keep everything but
+// the password, key=value connection strings everything but password=; any
+// other value is replaced entirely.
+func Redact(s string) string {
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		u.RawQuery = passwordParam.ReplaceAllString(u.RawQuery, "${1}"+redacted)
+		return u.Redacted()
+	}
+	if passwordParam.MatchString(s) {
+		return passwordParam.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return redacted
+}
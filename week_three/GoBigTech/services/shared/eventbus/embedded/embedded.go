@@ -0,0 +1,34 @@
+// Package embedded runs a NATS server inside the current process, for tests
+// and for local runs without Docker.
+package embedded
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// Start runs a NATS server with JetStream on host:port; port -1 picks a
+// free one. Streams are stored under storeDir. The client URL is available
+// through ClientURL.
+func Start(host string, port int, storeDir string) (*server.Server, error) {
+	srv, err := server.NewServer(&server.Options{
+		Host:      host,
+		Port:      port,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  storeDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		srv.Shutdown()
+		return nil, errors.New("embedded nats server did not start")
+	}
+	return srv, nil
+}
@@ -0,0 +1,44 @@
+// Package eventbus lets services exchange domain events asynchronously.
+//
+// Subjects are dot-separated tokens ("orders.OrderCancelled"); subscriptions
+// may use NATS wildcards: "*" matches one token, ">" matches the rest.
+package eventbus
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrClosed = errors.New("event bus closed")
+	// ErrDisconnected is returned by Publish while the broker is unreachable.
+	ErrDisconnected = errors.New("event bus disconnected")
+)
+
+type Message struct {
+	Subject string
+	Data    []byte
+}
+
+type Handler func(ctx context.Context, msg Message) error
+
+type Publisher interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+type Subscription interface {
+	Unsubscribe() error
+}
+
+type Subscriber interface {
+	// Subscribe delivers messages on subject to h. Subscriptions sharing a
+	// non-empty group split the messages between them, so every message is
+	// handled by one member of the group; an empty group receives all.
+	Subscribe(subject, group string, h Handler) (Subscription, error)
+}
+
+type Bus interface {
+	Publisher
+	Subscriber
+	Close() error
+}
@@ -0,0 +1,122 @@
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Memory is an in-process Bus. Publish delivers synchronously, which keeps
+// tests deterministic; handler errors are logged, like on a real broker.
+type Memory struct {
+	mu     sync.Mutex
+	subs   []*memorySub
+	next   map[string]int
+	closed bool
+}
+
+type memorySub struct {
+	bus     *Memory
+	subject string
+	group   string
+	handler Handler
+}
+
+func NewMemory() *Memory {
+	return &Memory{next: make(map[string]int)}
+}
+
+func (m *Memory) Publish(ctx context.Context, subject string, data []byte) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	targets := m.route(subject)
+	m.mu.Unlock()
+
+	msg := Message{Subject: subject, Data: data}
+	for _, s := range targets {
+		if err := s.handler(ctx, msg); err != nil {
+			slog.ErrorContext(ctx, "eventbus: handler failed", "subject", subject, "error", err)
+		}
+	}
+	return nil
+}
+
+// route picks every ungrouped subscription and one member of each group,
+// round-robin. m.mu must be held.
+func (m *Memory) route(subject string) []*memorySub {
+	var targets []*memorySub
+	groups := make(map[string][]*memorySub)
+	var order []string
+	for _, s := range m.subs {
+		if !matchSubject(s.subject, subject) {
+			continue
+		}
+		if s.group == "" {
+			targets = append(targets, s)
+			continue
+		}
+		key := s.subject + "\x00" + s.group
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+	for _, key := range order {
+		members := groups[key]
+		targets = append(targets, members[m.next[key]%len(members)])
+		m.next[key]++
+	}
+	return targets
+}
+
+func (m *Memory) Subscribe(subject, group string, h Handler) (Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, ErrClosed
+	}
+	s := &memorySub{bus: m, subject: subject, group: group, handler: h}
+	m.subs = append(m.subs, s)
+	return s, nil
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.subs = nil
+	return nil
+}
+
+func (s *memorySub) Unsubscribe() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for i, other := range s.bus.subs {
+		if other == s {
+			s.bus.subs = append(s.bus.subs[:i], s.bus.subs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func matchSubject(pattern, subject string) bool {
+	p := strings.Split(pattern, ".")
+	t := strings.Split(subject, ".")
+	for i, tok := range p {
+		if tok == ">" {
+			return len(t) > i
+		}
+		if i >= len(t) || (tok != "*" && tok != t[i]) {
+			return false
+		}
+	}
+	return len(p) == len(t)
+}
@@ -0,0 +1,91 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMatchSubject(t *testing.T) {
+	cases := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"orders.OrderCreated", "orders.OrderCreated", true},
+		{"orders.OrderCreated", "orders.OrderPaid", false},
+		{"orders.*", "orders.OrderPaid", true},
+		{"orders.*", "orders.OrderPaid.v2", false},
+		{"orders.>", "orders.OrderPaid.v2", true},
+		{"orders.>", "orders", false},
+		{"*.OrderPaid", "orders.OrderPaid", true},
+		{"orders", "orders.OrderPaid", false},
+	}
+	for _, c := range cases {
+		if got := matchSubject(c.pattern, c.subject); got != c.want {
+			t.Errorf("matchSubject(%q, %q) = %v, want %v", c.pattern, c.subject, got, c.want)
+		}
+	}
+}
+
+func TestMemory_FanOutAndQueueGroups(t *testing.T) {
+	bus := NewMemory()
+	ctx := context.Background()
+
+	var all, a, b int
+	_, _ = bus.Subscribe("orders.*", "", func(ctx context.Context, msg Message) error { all++; return nil })
+	_, _ = bus.Subscribe("orders.OrderCancelled", "inventory", func(ctx context.Context, msg Message) error { a++; return nil })
+	_, _ = bus.Subscribe("orders.OrderCancelled", "inventory", func(ctx context.Context, msg Message) error { b++; return nil })
+
+	for i := 0; i < 4; i++ {
+		if err := bus.Publish(ctx, "orders.OrderCancelled", []byte("{}")); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	if all != 4 {
+		t.Errorf("expected ungrouped subscriber to get 4 messages, got %d", all)
+	}
+	if a+b != 4 || a != 2 || b != 2 {
+		t.Errorf("expected queue group members to split 4 messages evenly, got %d and %d", a, b)
+	}
+}
+
+func TestMemory_HandlerErrorDoesNotFailPublish(t *testing.T) {
+	bus := NewMemory()
+
+	var got Message
+	_, _ = bus.Subscribe("orders.OrderPaid", "", func(ctx context.Context, msg Message) error {
+		return errors.New("boom")
+	})
+	_, _ = bus.Subscribe("orders.OrderPaid", "", func(ctx context.Context, msg Message) error {
+		got = msg
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), "orders.OrderPaid", []byte("x")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if got.Subject != "orders.OrderPaid" || string(got.Data) != "x" {
+		t.Errorf("unexpected message %+v", got)
+	}
+}
+
+func TestMemory_UnsubscribeAndClose(t *testing.T) {
+	bus := NewMemory()
+
+	calls := 0
+	sub, _ := bus.Subscribe("orders.>", "", func(ctx context.Context, msg Message) error { calls++; return nil })
+	_ = sub.Unsubscribe()
+	_ = bus.Publish(context.Background(), "orders.OrderPaid", nil)
+	if calls != 0 {
+		t.Errorf("expected no delivery after unsubscribe, got %d", calls)
+	}
+
+	_ = bus.Close()
+	if err := bus.Publish(context.Background(), "orders.OrderPaid", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if _, err := bus.Subscribe("orders.>", "", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed on subscribe, got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// DefaultStreamMaxAge is how long a stream keeps messages, whether or not
+	// they were consumed.
+	DefaultStreamMaxAge = 7 * 24 * time.Hour
+
+	// requestTimeout bounds the JetStream API calls made without a deadline
+	// and the wait for a publish acknowledgement.
+	requestTimeout = 5 * time.Second
+	// subscribeRetry is the pause before a failed consumer setup is retried.
+	subscribeRetry = time.Second
+	// nakDelay is the pause before a message whose handler failed is
+	// delivered again.
+	nakDelay = time.Second
+)
+
+// Stream is a JetStream stream storing the messages published to Subjects.
+// A subject without a stream cannot be published to.
+type Stream struct {
+	Name     string
+	Subjects []string
+}
+
+// NATS is a Bus backed by NATS JetStream. Publish returns only after the
+// server has stored the message, and subscribers acknowledge a message once
+// their handler succeeds, so delivery is at-least-once end to end: a failed
+// handler gets the message again, and messages published while nobody is
+// subscribed wait in the stream.
+type NATS struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	streams []Stream
+	closed  chan struct{}
+
+	mu      sync.Mutex
+	ensured bool
+	subs    []*natsSubscription
+}
+
+// ConnectNATS connects to url and declares streams, creating or updating
+// them on first use. The connection is retried in the background when the
+// server is not up yet, so services can start before the broker.
+func ConnectNATS(url, name string, streams ...Stream) (*NATS, error) {
+	closed := make(chan struct{})
+	conn, err := nats.Connect(url,
+		nats.Name(name),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(time.Second),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) }),
+	)
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn, jetstream.WithDefaultTimeout(requestTimeout))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &NATS{conn: conn, js: js, streams: streams, closed: closed}, nil
+}
+
+// ensureStreams creates or updates the declared streams once per connection.
+func (n *NATS) ensureStreams(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ensured {
+		return nil
+	}
+	for _, s := range n.streams {
+		_, err := n.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:     s.Name,
+			Subjects: s.Subjects,
+			Storage:  jetstream.FileStorage,
+			MaxAge:   DefaultStreamMaxAge,
+		})
+		if err != nil {
+			return fmt.Errorf("stream %s: %w", s.Name, err)
+		}
+	}
+	n.ensured = true
+	return nil
+}
+
+// Publish stores data in the stream of subject and waits for the server to
+// acknowledge it. It fails right away while the server is unreachable, and
+// when no stream takes the subject.
+func (n *NATS) Publish(ctx context.Context, subject string, data []byte) error {
+	if n.conn.IsClosed() {
+		return ErrClosed
+	}
+	if n.conn.Status() != nats.CONNECTED {
+		return ErrDisconnected
+	}
+	if err := n.ensureStreams(ctx); err != nil {
+		return err
+	}
+	_, err := n.js.Publish(ctx, subject, data)
+	if errors.Is(err, jetstream.ErrNoStreamResponse) {
+		// The server lost the stream; declare it again on the next call.
+		n.mu.Lock()
+		n.ensured = false
+		n.mu.Unlock()
+	}
+	return err
+}
+
+// Subscribe consumes subject through a durable consumer named after group
+// and subject, which keeps its position across restarts; an empty group
+// gets an ephemeral consumer receiving only new messages. The consumer is
+// set up in the background and retried until the server is reachable.
+func (n *NATS) Subscribe(subject, group string, h Handler) (Subscription, error) {
+	if n.conn.IsClosed() {
+		return nil, ErrClosed
+	}
+	cfg := jetstream.ConsumerConfig{
+		Durable:       durableName(group, subject),
+		FilterSubject: subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	}
+	if group == "" {
+		cfg.DeliverPolicy = jetstream.DeliverNewPolicy
+	}
+
+	s := &natsSubscription{stop: make(chan struct{}), done: make(chan struct{})}
+	n.mu.Lock()
+	n.subs = append(n.subs, s)
+	n.mu.Unlock()
+	go n.consume(s, cfg, h)
+	return s, nil
+}
+
+// subjectReplacer maps a subject to the characters allowed in a consumer
+// name.
+var subjectReplacer = strings.NewReplacer(".", "_", "*", "star", ">", "all")
+
+// durableName names the consumer of group on subject. A consumer has a
+// single filter, so one group subscribed to two subjects needs two
+// consumers; sharing one would make the second subscription replace the
+// filter of the first.
+func durableName(group, subject string) string {
+	if group == "" {
+		return ""
+	}
+	return group + "_" + subjectReplacer.Replace(subject)
+}
+
+func (n *NATS) consume(s *natsSubscription, cfg jetstream.ConsumerConfig, h Handler) {
+	defer close(s.done)
+
+	var cc jetstream.ConsumeContext
+	for failures := 0; cc == nil; failures++ {
+		var err error
+		cc, err = n.startConsumer(cfg, h)
+		if err != nil {
+			level := slog.LevelDebug
+			if failures == 0 {
+				level = slog.LevelWarn
+			}
+			slog.Log(context.Background(), level, "eventbus: subscribe failed, retrying", "subject", cfg.FilterSubject, "error", err)
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(subscribeRetry):
+			}
+		}
+	}
+
+	<-s.stop
+	cc.Drain()
+	<-cc.Closed()
+}
+
+func (n *NATS) startConsumer(cfg jetstream.ConsumerConfig, h Handler) (jetstream.ConsumeContext, error) {
+	if n.conn.Status() != nats.CONNECTED {
+		return nil, ErrDisconnected
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := n.ensureStreams(ctx); err != nil {
+		return nil, err
+	}
+	stream, err := n.js.StreamNameBySubject(ctx, cfg.FilterSubject)
+	if err != nil {
+		return nil, err
+	}
+	cons, err := n.js.CreateOrUpdateConsumer(ctx, stream, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cons.Consume(func(m jetstream.Msg) {
+		ctx := context.Background()
+		if err := h(ctx, Message{Subject: m.Subject(), Data: m.Data()}); err != nil {
+			slog.ErrorContext(ctx, "eventbus: handler failed", "subject", m.Subject(), "error", err)
+			_ = m.NakWithDelay(nakDelay)
+			return
+		}
+		if err := m.Ack(); err != nil {
+			slog.WarnContext(ctx, "eventbus: ack failed", "subject", m.Subject(), "error", err)
+		}
+	})
+}
+
+// Flush waits until the server has processed everything published so far.
+func (n *NATS) Flush(ctx context.Context) error {
+	return n.conn.FlushWithContext(ctx)
+}
+
+// Close stops the subscriptions, waits until the messages they already
+// received are handled and pending publishes are flushed, then closes the
+// connection.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	subs := n.subs
+	n.subs = nil
+	n.mu.Unlock()
+	for _, s := range subs {
+		_ = s.Unsubscribe()
+	}
+
+	err := n.conn.Drain()
+	if err != nil {
+		n.conn.Close()
+	}
+	<-n.closed
+	return err
+}
+
+type natsSubscription struct {
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Unsubscribe stops the delivery and waits for the running handlers. A
+// durable consumer stays on the server, so the group resumes where it left
+// off.
+func (s *natsSubscription) Unsubscribe() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return nil
+}
@@ -0,0 +1,199 @@
+package eventbus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus"
+	"github.com/bulbahal/GoBigTech/services/shared/eventbus/embedded"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+var testStream = eventbus.Stream{Name: "ORDERS", Subjects: []string{"orders.>"}}
+
+func startNATS(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := embedded.Start("127.0.0.1", -1, t.TempDir())
+	if err != nil {
+		t.Fatalf("start nats: %v", err)
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func connect(t *testing.T, url string) *eventbus.NATS {
+	t.Helper()
+	bus, err := eventbus.ConnectNATS(url, "test", testStream)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = bus.Close() })
+	return bus
+}
+
+func TestNATS_PublishSubscribe(t *testing.T) {
+	srv := startNATS(t)
+	bus := connect(t, srv.ClientURL())
+
+	got := make(chan eventbus.Message, 4)
+	handler := func(ctx context.Context, msg eventbus.Message) error {
+		got <- msg
+		return nil
+	}
+	if _, err := bus.Subscribe("orders.*", "inventory", handler); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := bus.Subscribe("orders.*", "inventory", handler); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if err := bus.Publish(ctx, "orders.OrderCancelled", []byte(`{"order_id":"o1"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case msg := <-got:
+		if msg.Subject != "orders.OrderCancelled" || string(msg.Data) != `{"order_id":"o1"}` {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("message not delivered")
+	}
+
+	select {
+	case msg := <-got:
+		t.Errorf("expected one delivery per queue group, got a second: %+v", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestNATS_KeepsMessagesUntilSubscribed(t *testing.T) {
+	srv := startNATS(t)
+	bus := connect(t, srv.ClientURL())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Publish(ctx, "orders.OrderCancelled", []byte(`{"order_id":"o1"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	got := make(chan eventbus.Message, 1)
+	if _, err := bus.Subscribe("orders.OrderCancelled", "inventory", func(ctx context.Context, msg eventbus.Message) error {
+		got <- msg
+		return nil
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	select {
+	case msg := <-got:
+		if string(msg.Data) != `{"order_id":"o1"}` {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("message published before the subscription was not delivered")
+	}
+}
+
+func TestNATS_GroupSubscribesToSeveralSubjects(t *testing.T) {
+	srv := startNATS(t)
+	bus := connect(t, srv.ClientURL())
+
+	cancelled := make(chan eventbus.Message, 4)
+	paid := make(chan eventbus.Message, 4)
+	for subject, got := range map[string]chan eventbus.Message{
+		"orders.OrderCancelled": cancelled,
+		"orders.OrderPaid":      paid,
+	} {
+		if _, err := bus.Subscribe(subject, "inventory", func(ctx context.Context, msg eventbus.Message) error {
+			got <- msg
+			return nil
+		}); err != nil {
+			t.Fatalf("subscribe %s: %v", subject, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, subject := range []string{"orders.OrderCancelled", "orders.OrderPaid"} {
+		if err := bus.Publish(ctx, subject, []byte(`{"order_id":"o1"}`)); err != nil {
+			t.Fatalf("publish %s: %v", subject, err)
+		}
+	}
+
+	for subject, got := range map[string]chan eventbus.Message{
+		"orders.OrderCancelled": cancelled,
+		"orders.OrderPaid":      paid,
+	} {
+		select {
+		case msg := <-got:
+			if msg.Subject != subject {
+				t.Errorf("subscription to %s got %s", subject, msg.Subject)
+			}
+		case <-ctx.Done():
+			t.Fatalf("%s not delivered", subject)
+		}
+	}
+}
+
+func TestNATS_RedeliversAfterHandlerError(t *testing.T) {
+	srv := startNATS(t)
+	bus := connect(t, srv.ClientURL())
+
+	calls := make(chan int, 4)
+	n := 0
+	if _, err := bus.Subscribe("orders.OrderCancelled", "inventory", func(ctx context.Context, msg eventbus.Message) error {
+		n++
+		calls <- n
+		if n == 1 {
+			return errors.New("mongo down")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := bus.Publish(ctx, "orders.OrderCancelled", []byte(`{"order_id":"o1"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for want := 1; want <= 2; want++ {
+		select {
+		case got := <-calls:
+			if got != want {
+				t.Fatalf("expected delivery %d, got %d", want, got)
+			}
+		case <-ctx.Done():
+			t.Fatalf("delivery %d did not happen", want)
+		}
+	}
+}
+
+func TestNATS_PublishFailsWhileBrokerDown(t *testing.T) {
+	bus := connect(t, "nats://127.0.0.1:1")
+
+	err := bus.Publish(context.Background(), "orders.OrderCancelled", []byte(`{}`))
+	if !errors.Is(err, eventbus.ErrDisconnected) {
+		t.Fatalf("expected ErrDisconnected, got %v", err)
+	}
+}
+
+func TestNATS_PublishFailsWithoutStream(t *testing.T) {
+	srv := startNATS(t)
+	bus := connect(t, srv.ClientURL())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Publish(ctx, "payments.Refunded", []byte(`{}`)); err == nil {
+		t.Fatal("expected a publish to a subject without a stream to fail")
+	}
+}
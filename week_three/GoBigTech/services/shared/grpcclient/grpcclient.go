@@ -0,0 +1,104 @@
+// Package grpcclient holds the call policy the services apply to the gRPC
+// services they depend on: a deadline for every attempt and retries with
+// exponential backoff for the calls that are safe to repeat.
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backoffMultiplier is the factor the backoff grows by after every retry.
+const backoffMultiplier = 2
+
+// RetryConfig is meant to be embedded in a service configuration as the
+// retry section of its gRPC clients.
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts" env:"GRPC_RETRY_MAX_ATTEMPTS" flag:"grpc-retry-max-attempts" default:"3" usage:"attempts of an idempotent gRPC call, the first one included"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env:"GRPC_RETRY_INITIAL_BACKOFF" flag:"grpc-retry-initial-backoff" default:"100ms" usage:"upper bound of the wait before the first retry"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env:"GRPC_RETRY_MAX_BACKOFF" flag:"grpc-retry-max-backoff" default:"1s" usage:"upper bound of the wait between two retries"`
+}
+
+func (c RetryConfig) Validate() error {
+	var errs []error
+	if c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("retry.max_attempts must be at least 1"))
+	}
+	if c.InitialBackoff <= 0 {
+		errs = append(errs, errors.New("retry.initial_backoff must be positive"))
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		errs = append(errs, errors.New("retry.max_backoff must not be less than retry.initial_backoff"))
+	}
+	return errors.Join(errs...)
+}
+
+// Backoff returns how long to wait before the given retry, counted from 1.
+// The wait is drawn uniformly from zero up to InitialBackoff doubled for
+// every earlier retry and capped at MaxBackoff, so clients that failed
+// together do not retry together.
+func (c RetryConfig) Backoff(retry int) time.Duration {
+	limit := c.InitialBackoff
+	for i := 1; i < retry && limit < c.MaxBackoff; i++ {
+		limit *= backoffMultiplier
+	}
+	limit = min(limit, c.MaxBackoff)
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit + 1)
+}
+
+// Retryable reports whether err is a transient failure worth another
+// attempt: the server could not be reached or did not answer in time.
+func Retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// UnaryClientInterceptor gives every attempt of a unary RPC its own deadline
+// of timeout; a deadline already on the context still bounds the whole call.
+// Calls to the idempotent methods, given as full method names, are retried
+// on transient failures until retry.MaxAttempts is reached or the context
+// is done. Every other call is made exactly once, since a repeated attempt
+// could apply it twice.
+func UnaryClientInterceptor(timeout time.Duration, retry RetryConfig, idempotent ...string) grpc.UnaryClientInterceptor {
+	retried := make(map[string]bool, len(idempotent))
+	for _, m := range idempotent {
+		retried[m] = true
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		attempts := 1
+		if retried[method] {
+			attempts = retry.MaxAttempts
+		}
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+			err = invoker(attemptCtx, method, req, reply, cc, opts...)
+			cancel()
+			if err == nil || attempt >= attempts || !Retryable(err) || ctx.Err() != nil {
+				return err
+			}
+
+			timer := time.NewTimer(retry.Backoff(attempt))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			}
+		}
+	}
+}
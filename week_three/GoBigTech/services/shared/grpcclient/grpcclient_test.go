@@ -0,0 +1,125 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	idempotentMethod = "/test.v1.Test/Release"
+	plainMethod      = "/test.v1.Test/Pay"
+)
+
+var testRetry = RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+// failingInvoker fails with code until it has been called failures times.
+type failingInvoker struct {
+	code      codes.Code
+	failures  int
+	calls     int
+	deadlines []time.Duration
+}
+
+func (f *failingInvoker) invoke(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+	f.calls++
+	if d, ok := ctx.Deadline(); ok {
+		f.deadlines = append(f.deadlines, time.Until(d))
+	}
+	if f.calls <= f.failures {
+		return status.Error(f.code, "failed")
+	}
+	return nil
+}
+
+func TestBackoffBounds(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	limits := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, limit := range limits {
+		for range 50 {
+			if got := cfg.Backoff(i + 1); got < 0 || got > limit {
+				t.Fatalf("Backoff(%d) = %v, want within [0, %v]", i+1, got, limit)
+			}
+		}
+	}
+}
+
+func TestRetriesIdempotentCall(t *testing.T) {
+	inv := &failingInvoker{code: codes.Unavailable, failures: 2}
+	intercept := UnaryClientInterceptor(time.Second, testRetry, idempotentMethod)
+
+	if err := intercept(context.Background(), idempotentMethod, nil, nil, nil, inv.invoke); err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	}
+	if inv.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", inv.calls)
+	}
+}
+
+func TestGivesUpAfterMaxAttempts(t *testing.T) {
+	inv := &failingInvoker{code: codes.DeadlineExceeded, failures: 10}
+	intercept := UnaryClientInterceptor(time.Second, testRetry, idempotentMethod)
+
+	err := intercept(context.Background(), idempotentMethod, nil, nil, nil, inv.invoke)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected the last error, got %v", err)
+	}
+	if inv.calls != testRetry.MaxAttempts {
+		t.Errorf("expected %d attempts, got %d", testRetry.MaxAttempts, inv.calls)
+	}
+}
+
+func TestDoesNotRetryNonIdempotentCall(t *testing.T) {
+	inv := &failingInvoker{code: codes.Unavailable, failures: 1}
+	intercept := UnaryClientInterceptor(time.Second, testRetry, idempotentMethod)
+
+	if err := intercept(context.Background(), plainMethod, nil, nil, nil, inv.invoke); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected the first error, got %v", err)
+	}
+	if inv.calls != 1 {
+		t.Errorf("expected a single attempt, got %d", inv.calls)
+	}
+}
+
+func TestDoesNotRetryPermanentError(t *testing.T) {
+	inv := &failingInvoker{code: codes.FailedPrecondition, failures: 1}
+	intercept := UnaryClientInterceptor(time.Second, testRetry, idempotentMethod)
+
+	if err := intercept(context.Background(), idempotentMethod, nil, nil, nil, inv.invoke); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected the first error, got %v", err)
+	}
+	if inv.calls != 1 {
+		t.Errorf("expected a single attempt, got %d", inv.calls)
+	}
+}
+
+func TestStopsWhenContextDone(t *testing.T) {
+	inv := &failingInvoker{code: codes.Unavailable, failures: 10}
+	slow := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	intercept := UnaryClientInterceptor(time.Second, slow, idempotentMethod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := intercept(ctx, idempotentMethod, nil, nil, nil, inv.invoke); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected the last error, got %v", err)
+	}
+	if inv.calls != 1 {
+		t.Errorf("expected no attempt after the context was done, got %d", inv.calls)
+	}
+}
+
+func TestAttemptDeadline(t *testing.T) {
+	inv := &failingInvoker{}
+	intercept := UnaryClientInterceptor(50*time.Millisecond, testRetry)
+
+	if err := intercept(context.Background(), plainMethod, nil, nil, nil, inv.invoke); err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.deadlines) != 1 || inv.deadlines[0] <= 0 || inv.deadlines[0] > 50*time.Millisecond {
+		t.Errorf("expected a deadline of at most 50ms, got %v", inv.deadlines)
+	}
+}
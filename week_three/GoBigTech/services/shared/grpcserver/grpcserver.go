@@ -0,0 +1,30 @@
+// Package grpcserver holds the pieces shared by the gRPC services.
+package grpcserver
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// GracefulStop stops srv from accepting new RPCs and waits up to timeout for
+// the running ones to finish; whatever is still running then is cancelled.
+// It reports whether every RPC finished in time.
+func GracefulStop(srv *grpc.Server, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		srv.Stop()
+		<-done
+		return false
+	}
+}
@@ -0,0 +1,111 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// blockingService is a hand-written service with a single unary method that
+// blocks until release is closed or the RPC is cancelled.
+type blockingService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingService) wait(ctx context.Context) error {
+	close(s.started)
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var blockingDesc = grpc.ServiceDesc{
+	ServiceName: "test.Blocking",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Wait",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			var in struct{}
+			_ = dec(&in)
+			return &struct{}{}, srv.(*blockingService).wait(ctx)
+		},
+	}},
+}
+
+type emptyCodec struct{}
+
+func (emptyCodec) Marshal(any) ([]byte, error) { return nil, nil }
+func (emptyCodec) Unmarshal([]byte, any) error { return nil }
+func (emptyCodec) Name() string                { return "empty" }
+
+func startServer(t *testing.T, svc *blockingService) (*grpc.Server, *grpc.ClientConn) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.ForceServerCodec(emptyCodec{}))
+	srv.RegisterService(&blockingDesc, svc)
+	go func() { _ = srv.Serve(l) }()
+
+	conn, err := grpc.NewClient(l.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(emptyCodec{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, conn
+}
+
+func call(conn *grpc.ClientConn) chan error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- conn.Invoke(context.Background(), "/test.Blocking/Wait", &struct{}{}, &struct{}{})
+	}()
+	return errc
+}
+
+func TestGracefulStop_WaitsForRunningRPC(t *testing.T) {
+	svc := &blockingService{started: make(chan struct{}), release: make(chan struct{})}
+	srv, conn := startServer(t, svc)
+
+	errc := call(conn)
+	<-svc.started
+	time.AfterFunc(50*time.Millisecond, func() { close(svc.release) })
+
+	if !GracefulStop(srv, 5*time.Second) {
+		t.Errorf("expected the RPC to finish in time")
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("expected the running RPC to succeed, got %v", err)
+	}
+}
+
+func TestGracefulStop_ForcesStopAfterTimeout(t *testing.T) {
+	svc := &blockingService{started: make(chan struct{}), release: make(chan struct{})}
+	srv, conn := startServer(t, svc)
+
+	errc := call(conn)
+	<-svc.started
+
+	start := time.Now()
+	if GracefulStop(srv, 100*time.Millisecond) {
+		t.Errorf("expected the drain to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stop took %v", elapsed)
+	}
+	if err := <-errc; err == nil {
+		t.Errorf("expected the RPC to be cancelled")
+	}
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCServer publishes the result of a Probe through grpc.health.v1. The
+// status applies to the whole server ("") and to each named service.
+type GRPCServer struct {
+	*grpchealth.Server
+
+	probe    *Probe
+	services []string
+}
+
+// NewGRPCServer returns a health server for p that starts NOT_SERVING until
+// the first run of the checks. Register it and start Watch.
+func NewGRPCServer(p *Probe, services ...string) *GRPCServer {
+	s := &GRPCServer{
+		Server:   grpchealth.NewServer(),
+		probe:    p,
+		services: append([]string{""}, services...),
+	}
+	s.set(healthpb.HealthCheckResponse_NOT_SERVING)
+	return s
+}
+
+// Register adds the health service to srv.
+func (s *GRPCServer) Register(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, s.Server)
+}
+
+// Watch runs the checks every interval and updates the published status
+// until ctx is done.
+func (s *GRPCServer) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	serving := true
+	for {
+		res := s.probe.Run(ctx)
+		switch {
+		case res.Ready:
+			if !serving {
+				slog.Info("health: serving")
+			}
+			s.set(healthpb.HealthCheckResponse_SERVING)
+		default:
+			if serving && !errors.Is(res.Err, ErrShuttingDown) {
+				slog.Warn("health: not serving", "error", res.Err)
+			}
+			s.set(healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+		serving = res.Ready
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown reports NOT_SERVING for every service from now on, so that
+// clients stop sending new RPCs while the server drains.
+func (s *GRPCServer) Shutdown() {
+	s.probe.Shutdown()
+	s.Server.Shutdown()
+}
+
+func (s *GRPCServer) set(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, name := range s.services {
+		s.SetServingStatus(name, status)
+	}
+}
+
+// GRPCCheck checks a remote server through its grpc.health.v1 service. An
+// empty service name asks about the server as a whole.
+func GRPCCheck(conn grpc.ClientConnInterface, service string) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", resp.GetStatus())
+		}
+		return nil
+	}
+}
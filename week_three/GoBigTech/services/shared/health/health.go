@@ -0,0 +1,106 @@
+// Package health runs readiness checks for the services and reports them
+// over HTTP (/healthz, /readyz) and the standard grpc.health.v1 service.
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShuttingDown is reported by a Probe once Shutdown has been called.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check reports whether a dependency can be used.
+type Check func(ctx context.Context) error
+
+// Probe runs a set of named checks. A service is ready when every check
+// passes and it is not shutting down.
+type Probe struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	checks map[string]Check
+
+	shuttingDown atomic.Bool
+}
+
+// NewProbe returns a Probe that gives each run of its checks timeout to
+// complete.
+func NewProbe(timeout time.Duration) *Probe {
+	return &Probe{timeout: timeout, checks: make(map[string]Check)}
+}
+
+// Add registers check under name, replacing any check with the same name.
+func (p *Probe) Add(name string, check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks[name] = check
+}
+
+// Shutdown marks the service as no longer ready. It is called when graceful
+// shutdown starts so that traffic is routed elsewhere while in-flight work
+// drains.
+func (p *Probe) Shutdown() {
+	p.shuttingDown.Store(true)
+}
+
+// Result is the outcome of one run of the checks. Checks maps each check
+// name to its error, nil for a passing check.
+type Result struct {
+	Ready  bool
+	Err    error
+	Checks map[string]error
+}
+
+// Run runs every check concurrently.
+func (p *Probe) Run(ctx context.Context) Result {
+	if p.shuttingDown.Load() {
+		return Result{Err: ErrShuttingDown}
+	}
+
+	p.mu.Lock()
+	checks := make(map[string]Check, len(p.checks))
+	for name, c := range p.checks {
+		checks[name] = c
+	}
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	res := Result{Ready: true, Checks: make(map[string]error, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c(ctx)
+			mu.Lock()
+			res.Checks[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	names := make([]string, 0, len(res.Checks))
+	for name := range res.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if err := res.Checks[name]; err != nil {
+			errs = append(errs, errors.New(name+": "+err.Error()))
+		}
+	}
+	res.Err = errors.Join(errs...)
+	res.Ready = res.Err == nil
+	return res
+}
@@ -0,0 +1,177 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func passing(ctx context.Context) error { return nil }
+
+func failing(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestProbe_Run(t *testing.T) {
+	p := NewProbe(time.Second)
+	p.Add("postgres", passing)
+	p.Add("inventory", passing)
+
+	if res := p.Run(context.Background()); !res.Ready || res.Err != nil {
+		t.Fatalf("expected ready, got %+v", res)
+	}
+
+	p.Add("payment", failing)
+	res := p.Run(context.Background())
+	if res.Ready {
+		t.Fatalf("expected not ready")
+	}
+	if res.Checks["payment"] == nil || res.Checks["postgres"] != nil {
+		t.Errorf("unexpected check results: %v", res.Checks)
+	}
+}
+
+func TestProbe_RunTimesOut(t *testing.T) {
+	p := NewProbe(20 * time.Millisecond)
+	p.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	res := p.Run(context.Background())
+	if res.Ready || !errors.Is(res.Checks["slow"], context.DeadlineExceeded) {
+		t.Errorf("expected the slow check to time out, got %+v", res)
+	}
+}
+
+func TestProbe_Shutdown(t *testing.T) {
+	p := NewProbe(time.Second)
+	p.Add("postgres", passing)
+	p.Shutdown()
+
+	res := p.Run(context.Background())
+	if res.Ready || !errors.Is(res.Err, ErrShuttingDown) {
+		t.Errorf("expected shutting down, got %+v", res)
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	p := NewProbe(time.Second)
+	p.Add("postgres", passing)
+
+	get := func() (int, report) {
+		rec := httptest.NewRecorder()
+		ReadyHandler(p).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var rep report
+		if err := json.NewDecoder(rec.Body).Decode(&rep); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return rec.Code, rep
+	}
+
+	if code, rep := get(); code != http.StatusOK || rep.Checks["postgres"] != statusOK {
+		t.Errorf("expected 200 with postgres ok, got %d %+v", code, rep)
+	}
+
+	p.Add("inventory", failing)
+	if code, rep := get(); code != http.StatusServiceUnavailable || rep.Status != statusDown || rep.Checks["inventory"] != statusDown {
+		t.Errorf("expected 503 with inventory down, got %d %+v", code, rep)
+	}
+
+	p.Shutdown()
+	if code, rep := get(); code != http.StatusServiceUnavailable || rep.Status != statusStopping {
+		t.Errorf("expected 503 shutting down, got %d %+v", code, rep)
+	}
+}
+
+func TestLiveHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	LiveHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestGRPCServer(t *testing.T) {
+	healthy := true
+	p := NewProbe(time.Second)
+	p.Add("mongo", func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("ping failed")
+		}
+		return nil
+	})
+
+	hs := NewGRPCServer(p, "inventory.v1.InventoryService")
+	srv := grpc.NewServer()
+	hs.Register(srv)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	check := GRPCCheck(conn, "inventory.v1.InventoryService")
+	ctx := context.Background()
+
+	if err := check(ctx); err == nil {
+		t.Errorf("expected NOT_SERVING before the first run")
+	}
+
+	watchCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		hs.Watch(watchCtx, time.Hour)
+		close(done)
+	}()
+	waitFor(t, func() bool { return check(ctx) == nil })
+	stop()
+	<-done
+
+	healthy = false
+	hs.Watch(cancelled(), time.Hour)
+	if err := check(ctx); err == nil {
+		t.Errorf("expected NOT_SERVING after a failed check")
+	}
+
+	healthy = true
+	hs.Shutdown()
+	hs.Watch(cancelled(), time.Hour)
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING after shutdown, got %s", resp.GetStatus())
+	}
+}
+
+func cancelled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	statusOK       = "ok"
+	statusDown     = "down"
+	statusStopping = "shutting_down"
+)
+
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// LiveHandler answers /healthz: the process is up and serving HTTP. It does
+// not look at dependencies, so a failing database does not get the service
+// restarted.
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, report{Status: statusOK})
+	})
+}
+
+// ReadyHandler answers /readyz with 200 when every check of p passes and 503
+// otherwise, listing each check as ok or down. Why a check failed is only
+// logged, since the error may name internal hosts.
+func ReadyHandler(p *Probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := p.Run(r.Context())
+		if errors.Is(res.Err, ErrShuttingDown) {
+			writeReport(w, http.StatusServiceUnavailable, report{Status: statusStopping})
+			return
+		}
+
+		rep := report{Status: statusOK, Checks: make(map[string]string, len(res.Checks))}
+		for name, err := range res.Checks {
+			if err != nil {
+				slog.WarnContext(r.Context(), "health: check failed", "check", name, "error", err)
+				rep.Checks[name] = statusDown
+				continue
+			}
+			rep.Checks[name] = statusOK
+		}
+		status := http.StatusOK
+		if !res.Ready {
+			rep.Status = statusDown
+			status = http.StatusServiceUnavailable
+		}
+		writeReport(w, status, rep)
+	})
+}
+
+func writeReport(w http.ResponseWriter, status int, rep report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(rep)
+}
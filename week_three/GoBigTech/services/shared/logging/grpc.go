@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys that carry the correlation fields between services.
+const (
+	MetadataRequestID = "x-request-id"
+	MetadataOrderID   = "x-order-id"
+	MetadataUserID    = "x-user-id"
+)
+
+// UnaryClientInterceptor sends the correlation fields of the call context
+// as gRPC metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		f := fieldsFrom(ctx)
+		var kv []string
+		if f.requestID != "" {
+			kv = append(kv, MetadataRequestID, f.requestID)
+		}
+		if f.orderID != "" {
+			kv = append(kv, MetadataOrderID, f.orderID)
+		}
+		if f.userID != "" {
+			kv = append(kv, MetadataUserID, f.userID)
+		}
+		if len(kv) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor puts the correlation fields from the incoming
+// metadata into the handler context and logs every RPC except health checks.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := first(md, MetadataRequestID); v != "" {
+				ctx = WithRequestID(ctx, v)
+			}
+			if v := first(md, MetadataOrderID); v != "" {
+				ctx = WithOrderID(ctx, v)
+			}
+			if v := first(md, MetadataUserID); v != "" {
+				ctx = WithUserID(ctx, v)
+			}
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if _, isHealth := req.(*healthpb.HealthCheckRequest); isHealth {
+			return resp, err
+		}
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK, codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition, codes.AlreadyExists:
+		default:
+			level = slog.LevelError
+		}
+		attrs := []any{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		slog.Log(ctx, level, "grpc request", attrs...)
+		return resp, err
+	}
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
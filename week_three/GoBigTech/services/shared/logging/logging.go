@@ -0,0 +1,147 @@
+// Package logging sets up structured log/slog logging for the services and
+// carries the correlation fields (request, order and user IDs) that every
+// log line of a request includes.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Keys of the correlation fields in log records.
+const (
+	KeyRequestID = "request_id"
+	KeyOrderID   = "order_id"
+	KeyUserID    = "user_id"
+	KeyTraceID   = "trace_id"
+)
+
+// Config is meant to be embedded in a service configuration as the log
+// section.
+type Config struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" flag:"log-level" default:"info" usage:"debug, info, warn or error"`
+	Format string `yaml:"format" env:"LOG_FORMAT" flag:"log-format" default:"json" usage:"json or text"`
+}
+
+func (c Config) Validate() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return fmt.Errorf("log.level: %w", err)
+	}
+	if c.Format != FormatJSON && c.Format != FormatText {
+		return fmt.Errorf("log.format must be %s or %s, got %q", FormatJSON, FormatText, c.Format)
+	}
+	return nil
+}
+
+// Setup makes a logger for the named service the slog default; the stdlib
+// log package writes through it as well.
+func Setup(service string, cfg Config) *slog.Logger {
+	logger := New(os.Stdout, service, cfg)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// New returns a logger writing to w that adds the service name and the
+// correlation fields found in the context of each record.
+func New(w io.Writer, service string, cfg Config) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(cfg.Level))
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	if strings.EqualFold(cfg.Format, FormatText) {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h}).With("service", service)
+}
+
+// Fatal logs msg at error level and exits.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type fields struct {
+	requestID string
+	orderID   string
+	userID    string
+}
+
+type fieldsKey struct{}
+
+func fieldsFrom(ctx context.Context) fields {
+	f, _ := ctx.Value(fieldsKey{}).(fields)
+	return f
+}
+
+// WithRequestID returns ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	f := fieldsFrom(ctx)
+	f.requestID = id
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// WithOrderID returns ctx carrying the order ID.
+func WithOrderID(ctx context.Context, id string) context.Context {
+	f := fieldsFrom(ctx)
+	f.orderID = id
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// WithUserID returns ctx carrying the user ID.
+func WithUserID(ctx context.Context, id string) context.Context {
+	f := fieldsFrom(ctx)
+	f.userID = id
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+func RequestID(ctx context.Context) string { return fieldsFrom(ctx).requestID }
+func OrderID(ctx context.Context) string   { return fieldsFrom(ctx).orderID }
+func UserID(ctx context.Context) string    { return fieldsFrom(ctx).userID }
+
+// contextHandler adds the correlation fields and the trace ID of the
+// record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		f := fieldsFrom(ctx)
+		if f.requestID != "" {
+			r.AddAttrs(slog.String(KeyRequestID, f.requestID))
+		}
+		if f.orderID != "" {
+			r.AddAttrs(slog.String(KeyOrderID, f.orderID))
+		}
+		if f.userID != "" {
+			r.AddAttrs(slog.String(KeyUserID, f.userID))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			r.AddAttrs(slog.String(KeyTraceID, sc.TraceID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
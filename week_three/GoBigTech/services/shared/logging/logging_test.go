@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (Config{Level: "debug", Format: FormatJSON}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (Config{Level: "loud", Format: FormatJSON}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown level")
+	}
+	if err := (Config{Level: "info", Format: "xml"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestNew_AddsCorrelationFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "order", Config{Level: "info", Format: FormatJSON})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithUserID(ctx, "u1")
+	ctx = WithOrderID(ctx, "o1")
+	logger.InfoContext(ctx, "order created", "total", 100)
+	logger.DebugContext(ctx, "hidden")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":        "order created",
+		"service":    "order",
+		KeyRequestID: "req-1",
+		KeyUserID:    "u1",
+		KeyOrderID:   "o1",
+		"total":      float64(100),
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+}
+
+func TestInterceptors_PropagateCorrelationFields(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithOrderID(ctx, "o1")
+	ctx = WithUserID(ctx, "u1")
+
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	if err := UnaryClientInterceptor()(ctx, "/inventory.v1.InventoryService/ReserveStock", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(New(&buf, "inventory", Config{Level: "info", Format: FormatJSON}))
+	defer slog.SetDefault(prev)
+
+	var got context.Context
+	handler := func(ctx context.Context, req any) (any, error) {
+		got = ctx
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/inventory.v1.InventoryService/ReserveStock"}
+	if _, err := UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), sent), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	if RequestID(got) != "req-1" || OrderID(got) != "o1" || UserID(got) != "u1" {
+		t.Errorf("fields not propagated: request=%q order=%q user=%q", RequestID(got), OrderID(got), UserID(got))
+	}
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	if line[KeyRequestID] != "req-1" || line["code"] != "OK" {
+		t.Errorf("unexpected access log line: %v", line)
+	}
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSplitMethod(t *testing.T) {
+	cases := []struct {
+		in, service, method string
+	}{
+		{"/inventory.v1.InventoryService/ReserveStock", "inventory.v1.InventoryService", "ReserveStock"},
+		{"/grpc.health.v1.Health/Check", "grpc.health.v1.Health", "Check"},
+		{"broken", "unknown", "broken"},
+	}
+	for _, c := range cases {
+		service, method := splitMethod(c.in)
+		if service != c.service || method != c.method {
+			t.Errorf("splitMethod(%q) = %q, %q; want %q, %q", c.in, service, method, c.service, c.method)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Server"}
+
+	ok := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	rejected := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.FailedPrecondition, "not enough stock")
+	}
+
+	_, _ = intercept(context.Background(), nil, info, ok)
+	_, _ = intercept(context.Background(), nil, info, ok)
+	if _, err := intercept(context.Background(), nil, info, rejected); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected the handler error to pass through, got %v", err)
+	}
+
+	if got := testutil.ToFloat64(grpcServerHandled.WithLabelValues("test.v1.Test", "Server", "OK")); got != 2 {
+		t.Errorf("expected 2 OK calls, got %v", got)
+	}
+	if got := testutil.ToFloat64(grpcServerHandled.WithLabelValues("test.v1.Test", "Server", "FailedPrecondition")); got != 1 {
+		t.Errorf("expected 1 FailedPrecondition call, got %v", got)
+	}
+	if got := testutil.CollectAndCount(grpcServerDuration, "grpc_server_handling_seconds"); got != 1 {
+		t.Errorf("expected one duration series, got %d", got)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	intercept := UnaryClientInterceptor()
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "connection refused")
+	}
+
+	if err := intercept(context.Background(), "/test.v1.Test/Client", nil, nil, nil, invoker); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected the call error to pass through, got %v", err)
+	}
+	if got := testutil.ToFloat64(grpcClientHandled.WithLabelValues("test.v1.Test", "Client", "Unavailable")); got != 1 {
+		t.Errorf("expected 1 Unavailable call, got %v", got)
+	}
+}
@@ -0,0 +1,31 @@
+// Package metrics exposes Prometheus metrics for the services: the /metrics
+// handler and RED metrics (rate, errors, duration) for gRPC calls.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where every service serves its metrics.
+const Path = "/metrics"
+
+// Handler serves the metrics of the default registry, which also holds the
+// Go runtime and process collectors.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// NewServer returns an HTTP server that serves only Path, for services whose
+// API is not HTTP. The caller starts it and shuts it down.
+func NewServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+Path, Handler())
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,108 @@
+// Package tracing sets up OpenTelemetry tracing for the services and the
+// gRPC instrumentation they share.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	ExporterNone = "none"
+	// ExporterStdout prints spans as JSON for local debugging. They go to
+	// stderr, since stdout carries the JSON logs that log shippers parse.
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects where spans go. It is meant to be embedded in a service
+// configuration as the tracing section.
+type Config struct {
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" default:"none" usage:"none, stdout (spans on stderr) or otlp"`
+	// Endpoint is the OTLP gRPC collector; empty means the exporter default,
+	// which also honours OTEL_EXPORTER_OTLP_ENDPOINT.
+	Endpoint string `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT" flag:"tracing-otlp-endpoint" usage:"OTLP gRPC collector address, host:port"`
+	Insecure bool   `yaml:"insecure" env:"TRACING_OTLP_INSECURE" flag:"tracing-otlp-insecure" default:"true" usage:"connect to the collector without TLS"`
+}
+
+func (c Config) Validate() error {
+	switch c.Exporter {
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+		return nil
+	}
+	return fmt.Errorf("tracing.exporter must be %s, %s or %s, got %q", ExporterNone, ExporterStdout, ExporterOTLP, c.Exporter)
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator for the named service. The returned function flushes pending
+// spans and must be called on shutdown. With ExporterNone incoming trace
+// context is still propagated, but no spans are recorded.
+func Setup(ctx context.Context, service string, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if cfg.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("tracing exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(service),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case ExporterOTLP:
+		var opts []otlptracegrpc.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+}
+
+// ServerHandler traces incoming RPCs, continuing the caller's trace. Health
+// checks are left out.
+func ServerHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))
+}
+
+// ClientHandler traces outgoing RPCs and passes the trace context along.
+// Health checks are left out.
+func ClientHandler() stats.Handler {
+	return otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// pingDesc is a hand-written service that answers every call, reusing the
+// health check messages.
+var pingDesc = grpc.ServiceDesc{
+	ServiceName: "test.v1.Test",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Ping",
+		Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			var req healthpb.HealthCheckRequest
+			if err := dec(&req); err != nil {
+				return nil, err
+			}
+			return &healthpb.HealthCheckResponse{}, nil
+		},
+	}},
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, exporter := range []string{ExporterNone, ExporterStdout, ExporterOTLP} {
+		if err := (Config{Exporter: exporter}).Validate(); err != nil {
+			t.Errorf("exporter %q: unexpected error %v", exporter, err)
+		}
+	}
+	if err := (Config{Exporter: "jaeger"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown exporter")
+	}
+}
+
+func TestSetup_Stdout(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "test", Config{Exporter: ExporterStdout})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown failed: %v", err)
+	}
+}
+
+// TestHandlers_PropagateTrace checks that a client span and the server span
+// it causes belong to one trace, and that health checks are not traced.
+func TestHandlers_PropagateTrace(t *testing.T) {
+	if _, err := Setup(context.Background(), "test", Config{Exporter: ExporterNone}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(prev)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.StatsHandler(ServerHandler()))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	srv.RegisterService(&pingDesc, nil)
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(l.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(ClientHandler()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := context.Background()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(recorder.Ended()); n != 0 {
+		t.Fatalf("expected health checks to be untraced, got %d spans", n)
+	}
+
+	err = conn.Invoke(ctx, "/test.v1.Test/Ping", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.GracefulStop()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected a client and a server span, got %d", len(spans))
+	}
+	if spans[0].SpanContext().TraceID() != spans[1].SpanContext().TraceID() {
+		t.Errorf("client and server spans are in different traces")
+	}
+}